@@ -11,7 +11,8 @@ if %errorlevel% neq 0 (
     echo ❌ ERROR: Cannot connect to database or database doesn't exist!
     echo.
     echo Creating database and tables from scratch...
-    mysql -u root -pVojislav123! < migrations\001_init.sql
+    mysql -u root -pVojislav123! -e "CREATE DATABASE IF NOT EXISTS app_db CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;"
+    mysql -u root -pVojislav123! app_db < migrations\001_init.up.sql
     if %errorlevel% equ 0 (
         echo ✅ Database and tables created!
     ) else (
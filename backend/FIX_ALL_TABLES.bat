@@ -5,7 +5,7 @@ echo ========================================
 echo.
 
 echo Fixing workouts and progress tables...
-mysql -u root -pVojislav123! app_db < migrations\003_fix_all_tables.sql
+mysql -u root -pVojislav123! app_db < migrations\003_fix_all_tables.up.sql
 
 if %errorlevel% neq 0 (
     echo.
@@ -5,7 +5,7 @@ echo ========================================
 echo.
 
 echo Adding progress_date column if missing...
-mysql -u root -pVojislav123! app_db < migrations\002_fix_progress_date.sql
+mysql -u root -pVojislav123! app_db < migrations\002_fix_progress_date.up.sql
 
 if %errorlevel% neq 0 (
     echo.
@@ -13,7 +13,8 @@ if "%DB_NAME%"=="" set DB_NAME=app_db
 
 echo.
 echo Running migration...
-mysql -u %DB_USER% -p%DB_PASS% < backend\migrations\001_init.sql
+mysql -u %DB_USER% -p%DB_PASS% -e "CREATE DATABASE IF NOT EXISTS %DB_NAME% CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;"
+mysql -u %DB_USER% -p%DB_PASS% %DB_NAME% < backend\migrations\001_init.up.sql
 
 echo.
 echo Migration completed!
@@ -18,7 +18,7 @@ mysql -u root -pVojislav123! app_db -e "DROP TABLE IF EXISTS progress; DROP TABL
 
 echo.
 echo Creating fresh tables...
-mysql -u root -pVojislav123! app_db < migrations\001_init.sql
+mysql -u root -pVojislav123! app_db < migrations\001_init.up.sql
 
 if %errorlevel% neq 0 (
     echo.
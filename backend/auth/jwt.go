@@ -1,28 +1,37 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtSecret = []byte(getJWTSecret())
+// jwtSecret je tajna za potpisivanje tokena; postavlja je Configure pri pokretanju
+var jwtSecret []byte
 
-func getJWTSecret() string {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "your-secret-key-change-in-production"
-	}
-	return secret
+// errNotConfigured se vraća ako tajna nije postavljena pre rada sa tokenima
+var errNotConfigured = errors.New("JWT secret is not configured")
+
+// Configure postavlja tajnu za potpisivanje i proveru tokena (JWT_SECRET iz konfiguracije)
+func Configure(secret string) {
+	jwtSecret = []byte(secret)
 }
 
+// purposeTwoFactor označava kratkotrajni token izdat posle lozinke, a pre drugog faktora
+const purposeTwoFactor = "2fa"
+
+// ChallengeTokenTTL je rok za unos koda drugog faktora posle uspešne lozinke
+const ChallengeTokenTTL = 5 * time.Minute
+
 // Claims predstavlja strukturu JWT zahteva
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
+	UserID  int    `json:"user_id"`
+	Email   string `json:"email"`
+	Purpose string `json:"purpose,omitempty"` // prazno za pristupni token
 	jwt.RegisteredClaims
 }
 
@@ -38,12 +47,68 @@ func GenerateToken(userID int, email string) (string, error) {
 		},
 	}
 
+	return signToken(claims)
+}
+
+// GenerateChallengeToken generiše token za drugi korak prijave; ne daje pristup API-ju.
+// Vraća i jti tokena, koji pozivalac čuva da bi token mogao da se iskoristi samo jednom.
+func GenerateChallengeToken(userID int, email string) (string, string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	claims := &Claims{
+		UserID:  userID,
+		Email:   email,
+		Purpose: purposeTwoFactor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ChallengeTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token, err := signToken(claims)
+	return token, claims.ID, err
+}
+
+func signToken(claims *Claims) (string, error) {
+	if len(jwtSecret) == 0 {
+		return "", errNotConfigured
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
 
-// ValidateToken verifikuje i parsira JWT token
+// ValidateToken verifikuje i parsira pristupni JWT token
 func ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// ValidateChallengeToken verifikuje token izdat za drugi korak prijave
+func ValidateChallengeToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purposeTwoFactor {
+		return nil, errors.New("invalid challenge token")
+	}
+	return claims, nil
+}
+
+func parseToken(tokenString string) (*Claims, error) {
+	if len(jwtSecret) == 0 {
+		return nil, errNotConfigured
+	}
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid signing method")
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parametri TOTP-a (RFC 6238) koje podržavaju sve authenticator aplikacije
+const (
+	totpDigits     = 6
+	totpModulo     = 1000000 // 10^totpDigits
+	totpPeriod     = 30 * time.Second
+	totpSecretSize = 20 // 160 bita, preporučena dužina ključa za HMAC-SHA1
+	// totpSkew je broj susednih koraka koji se prihvataju zbog razlike u satu telefona
+	totpSkew = 1
+)
+
+// RecoveryCodeCount je broj rezervnih kodova koji se izdaju pri uključivanju 2FA
+const RecoveryCodeCount = 10
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret generiše novu nasumičnu TOTP tajnu u base32 zapisu
+func GenerateTOTPSecret() (string, error) {
+	key := make([]byte, totpSecretSize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(key), nil
+}
+
+// TOTPURI vraća otpauth:// URI koji authenticator aplikacije čitaju iz QR koda
+func TOTPURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	// Neke aplikacije ne dekodiraju "+" kao razmak, pa se koristi %20
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// ValidateTOTP proverava kod za trenutak now i vraća korak (vremenski interval) kome
+// kod pripada. Koraci do lastStep uključivo se odbijaju, da isti kod ne bi mogao
+// da se iskoristi dva puta.
+func ValidateTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / int64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode računa HOTP vrednost (RFC 4226) za dati korak
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulo)
+}
+
+// GenerateRecoveryCodes generiše jednokratne rezervne kodove oblika xxxxx-xxxxx
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	buf := make([]byte, 7)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(buf))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode vraća heš rezervnog koda za čuvanje u bazi. Kodovi su nasumični
+// (50 bita), pa je SHA-256 dovoljan i omogućava pretragu po hešu.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret je ključ iz RFC 6238 dodatka B ("12345678901234567890") u base32 zapisu
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfcVectors su SHA-1 vektori iz RFC 6238; RFC daje 8 cifara, a ovde se koristi poslednjih 6
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+}
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	for _, tt := range rfcVectors {
+		if got := totpCode(key, tt.unix/30); got != tt.code {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTPRFC6238Vectors(t *testing.T) {
+	for _, tt := range rfcVectors {
+		step, ok := ValidateTOTP(rfcSecret, tt.code, time.Unix(tt.unix, 0), 0)
+		if !ok || step != tt.unix/30 {
+			t.Errorf("ValidateTOTP(T=%d) = %d, %v; want %d, true", tt.unix, step, ok, tt.unix/30)
+		}
+	}
+	// Tajna se prihvata i malim slovima, a kod sa okolnim razmacima
+	if _, ok := ValidateTOTP(strings.ToLower(rfcSecret), " 005924 ", time.Unix(1234567890, 0), 0); !ok {
+		t.Error("lowercase secret or padded code rejected")
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / 30
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   bool
+	}{
+		{"previous step", -1, true},
+		{"current step", 0, true},
+		{"next step", 1, true},
+		{"two steps behind", -2, false},
+		{"two steps ahead", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfcSecret, totpCode(key, current+tt.offset), now, 0)
+			if ok != tt.want {
+				t.Fatalf("ok = %v, want %v", ok, tt.want)
+			}
+			if ok && step != current+tt.offset {
+				t.Fatalf("step = %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPRejectsReplay(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / 30
+	code := totpCode(key, current)
+
+	step, ok := ValidateTOTP(rfcSecret, code, now, 0)
+	if !ok {
+		t.Fatal("first use rejected")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, code, now, step); ok {
+		t.Fatal("same code accepted twice (step <= lastStep)")
+	}
+	// Kod iz ranijeg koraka ne prolazi posle korišćenja novijeg
+	if _, ok := ValidateTOTP(rfcSecret, totpCode(key, current-1), now, step); ok {
+		t.Fatal("older step accepted after a newer one was used")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, totpCode(key, current+1), now, step); !ok {
+		t.Fatal("next step rejected after the current one was used")
+	}
+}
+
+func TestValidateTOTPRejectsMalformedInput(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	tests := []struct {
+		name, secret, code string
+	}{
+		{"too short", rfcSecret, "00592"},
+		{"too long", rfcSecret, "0059240"},
+		{"eight digit RFC code", rfcSecret, "89005924"},
+		{"empty", rfcSecret, ""},
+		{"invalid secret", "not base32!", "005924"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now, 0); ok {
+				t.Fatal("code accepted")
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != totpSecretSize {
+		t.Fatalf("secret %q decodes to %d bytes (err %v), want %d", secret, len(key), err, totpSecretSize)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Fitness App", "ana@example.com", rfcSecret)
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/Fitness App:ana@example.com" {
+		t.Fatalf("uri = %s", uri)
+	}
+	if strings.Contains(uri, "+") {
+		t.Fatalf("uri %s encodes spaces as '+'", uri)
+	}
+	if query := parsed.Query(); query.Get("secret") != rfcSecret || query.Get("issuer") != "Fitness App" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Fatalf("query = %v", query)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("got %d codes, want %d", len(codes), RecoveryCodeCount)
+	}
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q does not match xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCodeNormalization(t *testing.T) {
+	want := HashRecoveryCode("abcde-fghij")
+	for _, variant := range []string{"ABCDE-FGHIJ", "abcdefghij", " abcde-fghij ", "abcde fghij", "AbCdE - FgHiJ"} {
+		if got := HashRecoveryCode(variant); got != want {
+			t.Errorf("HashRecoveryCode(%q) differs from the canonical code", variant)
+		}
+	}
+	if HashRecoveryCode("abcde-fghik") == want {
+		t.Error("different codes hash to the same value")
+	}
+	if len(want) != 64 {
+		t.Errorf("hash length = %d, want 64 hex characters", len(want))
+	}
+}
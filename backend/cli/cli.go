@@ -0,0 +1,87 @@
+// Package cli sadrži komande binarnog fajla servera: pokretanje servera, migracije
+// i administrativne poslove. Sve komande koriste istu konfiguraciju (config.Load).
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"backend/config"
+	"backend/logging"
+)
+
+const usage = `Upotreba: server [komanda] [opcije]
+
+Komande:
+  serve                        pokreće HTTP server (podrazumevano)
+  migrate up                   primenjuje sve neprimenjene migracije
+  migrate down [-steps N]      vraća poslednjih N migracija (podrazumevano 1)
+  migrate status               prikazuje stanje migracija
+  user create                  kreira korisnika (-email, -name, -password, -goal, -role)
+  user promote                 menja ulogu korisnika (-email, -role)
+  user reset-password          postavlja novu lozinku (-email, -password)
+  user disable-2fa             isključuje dvofaktorsku autentifikaciju (-email)
+  seed                         kreira demo nalog sa treninzima i napretkom
+  export-user                  izvozi sve podatke korisnika u ZIP (-email ili -id, -out)
+
+Opcije pojedinačne komande: server <komanda> -h
+Konfiguracija: promenljive okruženja ili fajl iz CONFIG_FILE (videti .env.example)
+`
+
+// Run izvršava komandu iz argumenata komandne linije (bez imena programa)
+func Run(args []string) error {
+	command, rest := "serve", []string(nil)
+	if len(args) > 0 {
+		command, rest = args[0], args[1:]
+	}
+
+	var run func(*config.Config, []string) error
+	switch command {
+	case "serve":
+		run = func(cfg *config.Config, _ []string) error { return Serve(cfg) }
+	case "migrate":
+		run = runMigrate
+	case "user":
+		run = runUser
+	case "seed":
+		run = runSeed
+	case "export-user":
+		run = runExportUser
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	// Konfiguracija se učitava i proverava jednom, pre bilo kakvog rada
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	logging.Setup(cfg.LogFormat, cfg.LogLevel)
+
+	err = run(cfg, rest)
+
+	// -h kod pojedinačne komande nije greška
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+// subcommand vraća podkomandu i ostale argumente, ili grešku sa spiskom dozvoljenih
+func subcommand(command string, args []string, allowed ...string) (string, []string, error) {
+	if len(args) > 0 {
+		for _, name := range allowed {
+			if args[0] == name {
+				return name, args[1:], nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("usage: server %s <%s>", command, strings.Join(allowed, "|"))
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"backend/config"
+	"backend/controllers"
+	"backend/utils"
+)
+
+// runExportUser izvozi sve podatke korisnika u ZIP fajl, u istom formatu kao /api/account/export
+func runExportUser(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export-user", flag.ContinueOnError)
+	email := flags.String("email", "", "email korisnika")
+	userID := flags.Int("id", 0, "ID korisnika (umesto -email)")
+	out := flags.String("out", "", "izlazni fajl (podrazumevano account-export-<id>-<datum>.zip)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*email == "") == (*userID == 0) {
+		return errors.New("exactly one of -email or -id is required")
+	}
+
+	if err := utils.OpenDB(cfg.DB, false); err != nil {
+		return err
+	}
+	defer utils.CloseDB()
+
+	if *email != "" {
+		id, err := findUserID(*email)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user %s not found", *email)
+		} else if err != nil {
+			return err
+		}
+		*userID = id
+	} else {
+		var exists bool
+		if err := utils.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)", *userID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to look up user: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("user %d not found", *userID)
+		}
+	}
+
+	exportedAt := time.Now()
+	if *out == "" {
+		*out = fmt.Sprintf("account-export-%d-%s.zip", *userID, exportedAt.Format("2006-01-02"))
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *out, err)
+	}
+
+	if err := controllers.WriteAccountExport(file, *userID, exportedAt); err != nil {
+		file.Close()
+		os.Remove(*out)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *out, err)
+	}
+
+	fmt.Printf("Exported user %d to %s\n", *userID, *out)
+	return nil
+}
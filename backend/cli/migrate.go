@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"backend/config"
+	"backend/utils"
+)
+
+// runMigrate izvršava migrate up/down/status
+func runMigrate(cfg *config.Config, args []string) error {
+	action, rest, err := subcommand("migrate", args, "up", "down", "status")
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("migrate "+action, flag.ContinueOnError)
+	steps := 1
+	if action == "down" {
+		flags.IntVar(&steps, "steps", 1, "broj migracija koje se vraćaju")
+	}
+	if err := flags.Parse(rest); err != nil {
+		return err
+	}
+
+	// Samo migrate up sme da kreira bazu
+	if err := utils.OpenDB(cfg.DB, action == "up"); err != nil {
+		return err
+	}
+	defer utils.CloseDB()
+
+	switch action {
+	case "up":
+		return utils.RunMigrations()
+	case "down":
+		return utils.RollbackMigrations(steps)
+	default:
+		return printMigrationStatus()
+	}
+}
+
+// printMigrationStatus ispisuje tabelu sa stanjem svih migracija
+func printMigrationStatus() error {
+	states, err := utils.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(out, "VERSION\tSTATUS\tAPPLIED AT")
+	pending := 0
+	for _, state := range states {
+		status, appliedAt := "pending", ""
+		switch {
+		case state.Missing:
+			status = "applied (file missing)"
+		case state.Modified:
+			status = "applied (modified)"
+		case state.Applied:
+			status = "applied"
+		default:
+			pending++
+		}
+		if state.AppliedAt != nil {
+			appliedAt = state.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\n", state.Version, status, appliedAt)
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d migrations, %d pending\n", len(states), pending)
+	return nil
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"time"
+
+	"backend/auth"
+	"backend/config"
+	"backend/models"
+	"backend/utils"
+)
+
+// seedWeeks je broj nedelja istorije koje seed kreira za demo nalog
+const seedWeeks = 8
+
+// seedWorkout je šablon treninga koji se ponavlja svake nedelje
+type seedWorkout struct {
+	weekday      time.Weekday
+	name         string
+	activityType string
+	duration     int
+	intensity    int
+	distanceKm   *float64
+	exercises    []models.WorkoutExercise
+}
+
+func kg(value float64) *float64 { return &value }
+
+// seedPlan je nedeljni plan demo naloga: dva treninga snage i jedno trčanje
+var seedPlan = []seedWorkout{
+	{time.Monday, "Upper body", "strength_training", 60, 7, nil, []models.WorkoutExercise{
+		{Name: "Bench press", MuscleGroup: "chest", Sets: 4, Reps: 8, WeightKg: kg(60)},
+		{Name: "Barbell row", MuscleGroup: "back", Sets: 4, Reps: 8, WeightKg: kg(50)},
+		{Name: "Overhead press", MuscleGroup: "shoulders", Sets: 3, Reps: 10, WeightKg: kg(35)},
+	}},
+	{time.Wednesday, "Easy run", "running", 40, 5, kg(6.5), nil},
+	{time.Friday, "Lower body", "strength_training", 60, 8, nil, []models.WorkoutExercise{
+		{Name: "Back squat", MuscleGroup: "quads", Sets: 5, Reps: 5, WeightKg: kg(80)},
+		{Name: "Romanian deadlift", MuscleGroup: "hamstrings", Sets: 3, Reps: 10, WeightKg: kg(60)},
+		{Name: "Calf raise", MuscleGroup: "calves", Sets: 3, Reps: 15},
+	}},
+}
+
+// runSeed kreira demo nalog sa nekoliko nedelja treninga i merenja napretka
+func runSeed(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	email := flags.String("email", "demo@example.com", "email demo naloga")
+	password := flags.String("password", "", "lozinka demo naloga; ako se izostavi, generiše se i ispisuje")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := utils.OpenDB(cfg.DB, false); err != nil {
+		return err
+	}
+	defer utils.CloseDB()
+
+	if _, err := findUserID(*email); err == nil {
+		return fmt.Errorf("user %s already exists; seed only creates a new demo account", *email)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	plain, generated, err := passwordOrGenerate(*password)
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(plain)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// MET vrednosti se čitaju iz kataloga, kao pri unosu treninga kroz API
+	mets, err := utils.LoadActivityMETs()
+	if err != nil {
+		return err
+	}
+	for _, plan := range seedPlan {
+		if _, ok := mets[plan.activityType]; !ok {
+			return fmt.Errorf("activity type %q is missing from activity_types; run the migrations first", plan.activityType)
+		}
+	}
+
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	startWeight := 84.0
+	result, err := tx.Exec(
+		"INSERT INTO users (name, email, password, goal, role, height, weight, weekly_workout_target) VALUES (?, ?, ?, 'lose_weight', 'user', 180, ?, ?)",
+		"Demo User", *email, hash, startWeight, len(seedPlan),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create demo user: %w", err)
+	}
+	userID, _ := result.LastInsertId()
+
+	today := time.Now()
+	firstWeek := mondayOf(today).AddDate(0, 0, -7*(seedWeeks-1))
+	workouts, entries := 0, 0
+	for week := 0; week < seedWeeks; week++ {
+		monday := firstWeek.AddDate(0, 0, 7*week)
+		weight := math.Round((startWeight-0.4*float64(week))*10) / 10
+
+		if _, err := tx.Exec(
+			"INSERT INTO progress (user_id, weight, body_fat, muscle_mass, notes, progress_date) VALUES (?, ?, ?, ?, ?, ?)",
+			userID, weight, 22-0.3*float64(week), 36, "Seed data", monday.Format("2006-01-02"),
+		); err != nil {
+			return fmt.Errorf("failed to insert progress: %w", err)
+		}
+		entries++
+
+		for _, plan := range seedPlan {
+			day := monday.AddDate(0, 0, int(plan.weekday-time.Monday))
+			if day.After(today) {
+				continue
+			}
+			calories := utils.CaloriesFromMET(mets[plan.activityType], weight, float64(plan.duration))
+			result, err := tx.Exec(
+				"INSERT INTO workouts (user_id, name, activity_type, duration, intensity, calories_burned, calories_method, distance_km, workout_date) VALUES (?, ?, ?, ?, ?, ?, 'met', ?, ?)",
+				userID, plan.name, plan.activityType, plan.duration, plan.intensity, calories, plan.distanceKm, day.Format("2006-01-02"),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert workout: %w", err)
+			}
+			workoutID, _ := result.LastInsertId()
+
+			// Opterećenje raste 2.5 kg nedeljno (progresivno preopterećenje)
+			for i, exercise := range plan.exercises {
+				var weightKg *float64
+				if exercise.WeightKg != nil {
+					weightKg = kg(*exercise.WeightKg + 2.5*float64(week))
+				}
+				if _, err := tx.Exec(
+					"INSERT INTO workout_exercises (workout_id, position, name, muscle_group, sets, reps, weight_kg) VALUES (?, ?, ?, ?, ?, ?, ?)",
+					workoutID, i+1, exercise.Name, exercise.MuscleGroup, exercise.Sets, exercise.Reps, weightKg,
+				); err != nil {
+					return fmt.Errorf("failed to insert exercise: %w", err)
+				}
+			}
+			workouts++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit seed data: %w", err)
+	}
+
+	fmt.Printf("Created demo user %s (id %d) with %d workouts and %d progress entries\n", *email, userID, workouts, entries)
+	if generated {
+		fmt.Printf("Generated password: %s\n", plain)
+	}
+	return nil
+}
+
+// mondayOf vraća ponoć ponedeljka nedelje kojoj dan pripada
+func mondayOf(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, day.Location())
+}
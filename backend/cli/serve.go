@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"backend/auth"
+	"backend/config"
+	"backend/controllers"
+	"backend/jobs"
+	"backend/metrics"
+	"backend/routes"
+	"backend/utils"
+)
+
+// Serve inicijalizuje bazu (uz migracije, ako su uključene) i pokreće HTTP server.
+// Na SIGINT/SIGTERM server prestaje da prima konekcije, čeka zahteve u toku i
+// pozadinske poslove najduže SHUTDOWN_TIMEOUT, pa zatvara bazu.
+func Serve(cfg *config.Config) error {
+	cfg.LogSummary()
+
+	// Podsistemi dobijaju podešavanja iz konfiguracije pre prvog zahteva
+	auth.Configure(cfg.JWTSecret)
+	controllers.Configure(cfg)
+
+	// Inicijalizacija baze podataka
+	if err := utils.InitDB(cfg.DB); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer closeDB()
+	metrics.RegisterDBStats(func() sql.DBStats { return utils.DB.Stats() })
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// Pozadinski posao za trajno brisanje naloga kojima je istekao period odlaganja
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	var background sync.WaitGroup
+	jobs.StartAccountPurger(jobsCtx, &background, cfg.AccountPurgeInterval)
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           routes.SetupRoutes(cfg),
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	// Pokretanje servera
+	slog.Info("server starting", "addr", server.Addr)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		stopJobs()
+		background.Wait()
+		return fmt.Errorf("failed to start server: %w", err)
+	case <-signals.Done():
+	}
+	// Drugi signal tokom gašenja prekida proces odmah
+	stopSignals()
+
+	slog.Info("shutting down", "timeout", cfg.HTTP.ShutdownTimeout.String())
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	stopJobs()
+	var shutdownErr error
+	if err := server.Shutdown(ctx); err != nil {
+		shutdownErr = fmt.Errorf("failed to drain HTTP connections: %w", err)
+		server.Close()
+	} else {
+		slog.Info("in-flight requests drained")
+	}
+
+	if err := waitForJobs(ctx, &background); err != nil {
+		shutdownErr = errors.Join(shutdownErr, err)
+	} else {
+		slog.Info("background jobs stopped")
+	}
+
+	return shutdownErr
+}
+
+// waitForJobs čeka da se pozadinski poslovi završe, najduže do isteka ctx
+func waitForJobs(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("background jobs did not stop before the shutdown deadline")
+	}
+}
+
+// closeDB zatvara pool konekcija sa bazom i beleži ishod
+func closeDB() {
+	if err := utils.CloseDB(); err != nil {
+		slog.Error("error closing database", "error", err)
+		return
+	}
+	slog.Info("database connection closed")
+}
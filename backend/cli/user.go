@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"backend/auth"
+	"backend/config"
+	"backend/utils"
+)
+
+var (
+	validRoles = map[string]bool{"user": true, "premium": true, "admin": true}
+	validGoals = map[string]bool{"lose_weight": true, "hypertrophy": true}
+)
+
+// runUser izvršava user create/promote/reset-password/disable-2fa
+func runUser(cfg *config.Config, args []string) error {
+	action, rest, err := subcommand("user", args, "create", "promote", "reset-password", "disable-2fa")
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("user "+action, flag.ContinueOnError)
+	email := flags.String("email", "", "email korisnika (obavezno)")
+	var name, password, goal, role *string
+	switch action {
+	case "create":
+		name = flags.String("name", "", "ime korisnika (obavezno)")
+		password = flags.String("password", "", "lozinka; ako se izostavi, generiše se i ispisuje")
+		goal = flags.String("goal", "lose_weight", "cilj: lose_weight ili hypertrophy")
+		role = flags.String("role", "user", "uloga: user, premium ili admin")
+	case "promote":
+		role = flags.String("role", "admin", "nova uloga: user, premium ili admin")
+	case "reset-password":
+		password = flags.String("password", "", "nova lozinka; ako se izostavi, generiše se i ispisuje")
+	}
+	if err := flags.Parse(rest); err != nil {
+		return err
+	}
+
+	*email = strings.TrimSpace(*email)
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+	if role != nil && !validRoles[*role] {
+		return fmt.Errorf("invalid role %q: use user, premium or admin", *role)
+	}
+
+	if err := utils.OpenDB(cfg.DB, false); err != nil {
+		return err
+	}
+	defer utils.CloseDB()
+
+	switch action {
+	case "create":
+		return createUser(*email, *name, *password, *goal, *role)
+	case "promote":
+		return promoteUser(*email, *role)
+	case "disable-2fa":
+		return disableTwoFactor(*email)
+	default:
+		return resetPassword(*email, *password)
+	}
+}
+
+// createUser kreira korisnika; lozinka se generiše ako nije zadata
+func createUser(email, name, password, goal, role string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("-name is required")
+	}
+	if !validGoals[goal] {
+		return fmt.Errorf("invalid goal %q: use lose_weight or hypertrophy", goal)
+	}
+	if _, err := findUserID(email); err == nil {
+		return fmt.Errorf("user %s already exists", email)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	password, generated, err := passwordOrGenerate(password)
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	result, err := utils.DB.Exec(
+		"INSERT INTO users (name, email, password, goal, role) VALUES (?, ?, ?, ?, ?)",
+		strings.TrimSpace(name), email, hash, goal, role,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	userID, _ := result.LastInsertId()
+
+	fmt.Printf("Created %s user %s (id %d)\n", role, email, userID)
+	if generated {
+		fmt.Printf("Generated password: %s\n", password)
+	}
+	return nil
+}
+
+// promoteUser menja ulogu postojećeg korisnika
+func promoteUser(email, role string) error {
+	result, err := utils.DB.Exec("UPDATE users SET role = ? WHERE email = ?", role, email)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		if _, err := findUserID(email); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user %s not found", email)
+		}
+		fmt.Printf("User %s already has role %s\n", email, role)
+		return nil
+	}
+
+	fmt.Printf("User %s now has role %s\n", email, role)
+	return nil
+}
+
+// resetPassword postavlja novu lozinku postojećem korisniku i otključava nalog
+func resetPassword(email, password string) error {
+	if _, err := findUserID(email); errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %s not found", email)
+	} else if err != nil {
+		return err
+	}
+
+	password, generated, err := passwordOrGenerate(password)
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if _, err := utils.DB.Exec("UPDATE users SET password = ?, failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL WHERE email = ?", hash, email); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	fmt.Printf("Password for %s has been reset\n", email)
+	if generated {
+		fmt.Printf("Generated password: %s\n", password)
+	}
+	return nil
+}
+
+// disableTwoFactor isključuje 2FA korisniku koji je izgubio uređaj i rezervne kodove
+func disableTwoFactor(email string) error {
+	userID, err := findUserID(email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %s not found", email)
+	} else if err != nil {
+		return err
+	}
+
+	if err := utils.ClearTwoFactor(userID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Two-factor authentication disabled for %s\n", email)
+	return nil
+}
+
+// findUserID vraća ID korisnika po email-u (sql.ErrNoRows ako ne postoji)
+func findUserID(email string) (int, error) {
+	var userID int
+	err := utils.DB.QueryRow("SELECT id FROM users WHERE email = ?", email).Scan(&userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to look up user: %w", err)
+	}
+	return userID, err
+}
+
+// passwordOrGenerate vraća zadatu lozinku ili generiše nasumičnu od 16 znakova
+func passwordOrGenerate(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), true, nil
+}
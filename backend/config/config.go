@@ -0,0 +1,426 @@
+// Package config učitava i proverava konfiguraciju servera. Vrednosti dolaze iz
+// environment promenljivih i opcionog KEY=VALUE fajla; environment ima prednost.
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Okruženja u kojima server radi. Nesigurne podrazumevane vrednosti (lozinka baze,
+// JWT tajna, CORS) dozvoljene su samo u razvojnom okruženju.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Podrazumevane vrednosti koje važe samo za razvoj (APP_ENV=development)
+const (
+	devDBPassword = "Vojislav123!"
+	devJWTSecret  = "your-secret-key-change-in-production"
+)
+
+var devAllowedOrigins = []string{
+	"http://localhost:3000", "http://localhost:5173", "http://localhost:5174", "http://localhost:8081",
+}
+
+// minJWTSecretLength je najmanja dužina JWT tajne van razvojnog okruženja
+const minJWTSecretLength = 32
+
+var dbNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// DBConfig su podešavanja konekcije sa bazom i migracija
+type DBConfig struct {
+	User          string
+	Password      string
+	Host          string
+	Port          int
+	Name          string
+	AutoMigrate   bool   // primena migracija pri pokretanju servera
+	StrictSchema  bool   // odbijanje pokretanja nad zaostalom šemom
+	MigrationsDir string // prazno: migracije ugrađene u binarni fajl
+}
+
+// HTTPConfig su vremenska ograničenja HTTP servera i gašenja
+type HTTPConfig struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration // rok za završetak zahteva u toku i pozadinskih poslova pri gašenju
+	TransferTimeout   time.Duration // rok za čitanje i pisanje na rutama za uvoz i izvoz
+}
+
+// RateRule je ograničenje broja zahteva: Requests zahteva na svakih Per (token bucket
+// sa kapacitetom Requests). Requests 0 isključuje ograničenje.
+type RateRule struct {
+	Requests int
+	Per      time.Duration
+}
+
+// RateLimitConfig su ograničenja po grupama ruta i zaključavanje naloga posle neuspelih prijava
+type RateLimitConfig struct {
+	Auth       RateRule // prijava i registracija, po IP adresi i po nalogu
+	API        RateRule // zaštićene rute, po IP adresi i po korisniku
+	Heavy      RateRule // uvoz i izvoz, po korisniku
+	TrustProxy bool     // IP klijenta iz X-Forwarded-For (samo iza proxy-ja)
+
+	LockoutThreshold int           // broj uzastopnih neuspelih prijava pre zaključavanja
+	LockoutBase      time.Duration // prvo zaključavanje; svako sledeće je duplo duže
+	LockoutMax       time.Duration
+}
+
+// Config je kompletna konfiguracija servera
+type Config struct {
+	Env                  string
+	Port                 int
+	HTTP                 HTTPConfig
+	DB                   DBConfig
+	JWTSecret            string
+	AllowedOrigins       []string // "*" dozvoljava sve
+	AccountDeletionGrace time.Duration
+	AccountPurgeInterval time.Duration
+	OpenAPIPath          string // prazno: specifikacija ugrađena u binarni fajl
+	HealthCheckTimeout   time.Duration
+	HealthCheckFood      bool   // provera Open Food Facts API-ja u /health/ready
+	MetricsToken         string // prazno: /metrics je javan
+	LogFormat            string // json ili text
+	LogLevel             string // debug, info, warn ili error
+	RateLimit            RateLimitConfig
+	TOTPIssuer           string // naziv aplikacije u authenticator aplikaciji
+	Source               string // fajl iz koga su učitane vrednosti, ako postoji
+}
+
+// IsDevelopment vraća true u razvojnom okruženju
+func (c *Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
+}
+
+// Load učitava konfiguraciju iz fajla (CONFIG_FILE, ili .env ako postoji) i environment
+// promenljivih, pa je proverava. Vraća sve pronađene greške odjednom.
+func Load() (*Config, error) {
+	fileValues, source, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &loader{file: fileValues}
+	cfg := &Config{
+		Env:  l.string("APP_ENV", EnvProduction),
+		Port: l.int("PORT", 8080),
+		HTTP: HTTPConfig{
+			ReadTimeout:       l.duration("HTTP_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout: l.duration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:      l.duration("HTTP_WRITE_TIMEOUT", 60*time.Second),
+			IdleTimeout:       l.duration("HTTP_IDLE_TIMEOUT", 120*time.Second),
+			ShutdownTimeout:   l.duration("SHUTDOWN_TIMEOUT", 20*time.Second),
+			TransferTimeout:   l.duration("HTTP_TRANSFER_TIMEOUT", 10*time.Minute),
+		},
+		DB: DBConfig{
+			User:          l.string("DB_USER", "root"),
+			Password:      l.string("DB_PASSWORD", ""),
+			Host:          l.string("DB_HOST", "127.0.0.1"),
+			Port:          l.int("DB_PORT", 3306),
+			Name:          l.string("DB_NAME", "app_db"),
+			AutoMigrate:   l.bool("DB_AUTO_MIGRATE", true),
+			StrictSchema:  l.bool("DB_STRICT_SCHEMA", false),
+			MigrationsDir: l.string("MIGRATIONS_DIR", ""),
+		},
+		JWTSecret:            l.string("JWT_SECRET", ""),
+		AllowedOrigins:       l.list("ALLOWED_ORIGINS", l.list("ALLOWED_ORIGIN", nil)),
+		AccountDeletionGrace: time.Duration(l.int("ACCOUNT_DELETION_GRACE_DAYS", 30)) * 24 * time.Hour,
+		AccountPurgeInterval: l.duration("ACCOUNT_PURGE_INTERVAL", time.Hour),
+		OpenAPIPath:          l.string("OPENAPI_PATH", ""),
+		HealthCheckTimeout:   l.duration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+		HealthCheckFood:      l.bool("HEALTH_CHECK_FOOD_PROVIDER", false),
+		MetricsToken:         l.string("METRICS_TOKEN", ""),
+		LogFormat:            l.string("LOG_FORMAT", "json"),
+		LogLevel:             l.string("LOG_LEVEL", "info"),
+		TOTPIssuer:           l.string("TOTP_ISSUER", "Fitness App"),
+		Source:               source,
+		RateLimit: RateLimitConfig{
+			Auth:             l.rate("RATE_LIMIT_AUTH", RateRule{10, time.Minute}),
+			API:              l.rate("RATE_LIMIT_API", RateRule{300, time.Minute}),
+			Heavy:            l.rate("RATE_LIMIT_HEAVY", RateRule{20, time.Hour}),
+			TrustProxy:       l.bool("TRUST_PROXY_HEADERS", false),
+			LockoutThreshold: l.int("LOGIN_LOCKOUT_THRESHOLD", 5),
+			LockoutBase:      l.duration("LOGIN_LOCKOUT_BASE", time.Minute),
+			LockoutMax:       l.duration("LOGIN_LOCKOUT_MAX", time.Hour),
+		},
+	}
+	if len(l.errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(l.errs...))
+	}
+
+	if cfg.IsDevelopment() {
+		cfg.applyDevelopmentDefaults()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyDevelopmentDefaults popunjava vrednosti koje su u razvoju opcione
+func (c *Config) applyDevelopmentDefaults() {
+	if c.DB.Password == "" {
+		c.DB.Password = devDBPassword
+	}
+	if c.JWTSecret == "" {
+		c.JWTSecret = devJWTSecret
+	}
+	if len(c.AllowedOrigins) == 0 {
+		c.AllowedOrigins = devAllowedOrigins
+	}
+}
+
+// Validate proverava sve vrednosti i vraća sve greške odjednom
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		fail("APP_ENV must be %q or %q, got %q", EnvDevelopment, EnvProduction, c.Env)
+	}
+	if c.RateLimit.LockoutThreshold < 1 {
+		fail("LOGIN_LOCKOUT_THRESHOLD must be at least 1")
+	}
+	if c.RateLimit.LockoutMax < c.RateLimit.LockoutBase {
+		fail("LOGIN_LOCKOUT_MAX must not be shorter than LOGIN_LOCKOUT_BASE")
+	}
+	if strings.TrimSpace(c.TOTPIssuer) == "" || strings.Contains(c.TOTPIssuer, ":") {
+		fail("TOTP_ISSUER must be non-empty and must not contain ':'")
+	}
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		fail("LOG_FORMAT must be json or text, got %q", c.LogFormat)
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		fail("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		fail("PORT must be between 1 and 65535")
+	}
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", c.HTTP.ReadTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", c.HTTP.ReadHeaderTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.HTTP.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.HTTP.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.HTTP.ShutdownTimeout},
+		{"HTTP_TRANSFER_TIMEOUT", c.HTTP.TransferTimeout},
+		{"HEALTH_CHECK_TIMEOUT", c.HealthCheckTimeout},
+		{"LOGIN_LOCKOUT_BASE", c.RateLimit.LockoutBase},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			fail("%s must be positive", timeout.key)
+		}
+	}
+	if c.DB.Port < 1 || c.DB.Port > 65535 {
+		fail("DB_PORT must be between 1 and 65535")
+	}
+	if c.DB.User == "" || c.DB.Host == "" {
+		fail("DB_USER and DB_HOST must not be empty")
+	}
+	if !dbNamePattern.MatchString(c.DB.Name) {
+		fail("DB_NAME may contain only letters, digits and underscores")
+	}
+	if c.AccountDeletionGrace < 0 {
+		fail("ACCOUNT_DELETION_GRACE_DAYS must not be negative")
+	}
+	if c.AccountPurgeInterval < time.Minute {
+		fail("ACCOUNT_PURGE_INTERVAL must be at least 1m")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || strings.Trim(parsed.Path, "/") != "" {
+			fail("ALLOWED_ORIGINS entry %q must look like https://example.com", origin)
+		}
+	}
+
+	// Van razvoja nema nesigurnih podrazumevanih vrednosti
+	if !c.IsDevelopment() {
+		if c.DB.Password == "" {
+			fail("DB_PASSWORD is required outside development (set APP_ENV=development for local defaults)")
+		}
+		if c.JWTSecret == devJWTSecret || len(c.JWTSecret) < minJWTSecretLength {
+			fail("JWT_SECRET must be set to a random value of at least %d characters outside development", minJWTSecretLength)
+		}
+		if len(c.AllowedOrigins) == 0 {
+			fail("ALLOWED_ORIGINS is required outside development (comma-separated origins, or * to allow any)")
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// LogSummary loguje konfiguraciju bez tajnih vrednosti
+func (c *Config) LogSummary() {
+	source := "environment"
+	if c.Source != "" {
+		source = "environment + " + c.Source
+	}
+	slog.Info("configuration loaded",
+		"source", source, "env", c.Env, "port", c.Port,
+		"db", fmt.Sprintf("%s@%s:%d/%s", c.DB.User, c.DB.Host, c.DB.Port, c.DB.Name),
+		"auto_migrate", c.DB.AutoMigrate, "strict_schema", c.DB.StrictSchema,
+		"origins", strings.Join(c.AllowedOrigins, ","), "log_format", c.LogFormat, "log_level", c.LogLevel)
+	if c.IsDevelopment() {
+		slog.Warn("development environment: insecure defaults are allowed")
+	}
+}
+
+// readConfigFile čita KEY=VALUE fajl iz CONFIG_FILE, ili .env iz radnog foldera ako postoji
+func readConfigFile() (map[string]string, string, error) {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		if _, err := os.Stat(".env"); err != nil {
+			return nil, "", nil
+		}
+		path = ".env"
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, "", fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return values, path, nil
+}
+
+// loader čita tipizovane vrednosti i skuplja greške parsiranja
+type loader struct {
+	file map[string]string
+	errs []error
+}
+
+func (l *loader) lookup(key string) (string, bool) {
+	if value := os.Getenv(key); value != "" {
+		return value, true
+	}
+	value, ok := l.file[key]
+	return value, ok && value != ""
+}
+
+func (l *loader) string(key, defaultValue string) string {
+	if value, ok := l.lookup(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (l *loader) int(key string, defaultValue int) int {
+	value, ok := l.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+func (l *loader) bool(key string, defaultValue bool) bool {
+	value, ok := l.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+func (l *loader) duration(key string, defaultValue time.Duration) time.Duration {
+	value, ok := l.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be a duration like 30m or 1h, got %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// rate čita ograničenje u obliku N/trajanje (npr. 10/1m); 0 isključuje ograničenje
+func (l *loader) rate(key string, defaultValue RateRule) RateRule {
+	value, ok := l.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	if value == "0" {
+		return RateRule{}
+	}
+	count, period, found := strings.Cut(value, "/")
+	requests, err := strconv.Atoi(count)
+	per, perErr := time.ParseDuration(period)
+	if !found || err != nil || perErr != nil || requests < 1 || per <= 0 {
+		l.errs = append(l.errs, fmt.Errorf("%s must look like 10/1m (requests/duration) or 0 to disable, got %q", key, value))
+		return defaultValue
+	}
+	return RateRule{Requests: requests, Per: per}
+}
+
+// list čita listu odvojenu zarezima
+func (l *loader) list(key string, defaultValue []string) []string {
+	value, ok := l.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, strings.TrimSuffix(item, "/"))
+		}
+	}
+	return items
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/auth"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+// ExportAccount izvozi sve podatke korisnika kao ZIP arhivu sa jednim JSON fajlom po tabeli
+func ExportAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	exportedAt := time.Now()
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"account-export-%s.zip\"", exportedAt.Format("2006-01-02")))
+
+	if err := WriteAccountExport(w, userID, exportedAt); err != nil {
+		// Zaglavlje je već poslato, pa se greška samo loguje i arhiva ostaje nekompletna
+		slog.ErrorContext(r.Context(), "error exporting account data", "error", err)
+		return
+	}
+	slog.InfoContext(r.Context(), "exported account data")
+}
+
+// WriteAccountExport upisuje ZIP arhivu sa svim podacima korisnika u out.
+// Koriste je API i komanda export-user.
+func WriteAccountExport(out io.Writer, userID int, exportedAt time.Time) error {
+	archive := zip.NewWriter(out)
+	files := make([]string, 0, len(utils.UserDataTables))
+	for _, table := range utils.UserDataTables {
+		entry, err := archive.Create(table.Name + ".json")
+		if err != nil {
+			return fmt.Errorf("failed to create archive entry %s: %w", table.Name, err)
+		}
+		if err := writeJSONRows(entry, table.ExportQuery, userID); err != nil {
+			return fmt.Errorf("failed to export %s: %w", table.Name, err)
+		}
+		files = append(files, table.Name+".json")
+	}
+
+	manifest, err := archive.Create("manifest.json")
+	if err != nil {
+		return fmt.Errorf("failed to create archive manifest: %w", err)
+	}
+	json.NewEncoder(manifest).Encode(map[string]interface{}{
+		"format_version": 1,
+		"user_id":        userID,
+		"exported_at":    exportedAt.Format(time.RFC3339),
+		"files":          files,
+	})
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return nil
+}
+
+// writeJSONRows streamuje rezultat upita kao JSON niz objekata (kolona -> vrednost)
+func writeJSONRows(out io.Writer, query string, userID int) error {
+	rows, err := utils.DB.Query(query, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(out, "[\n"); err != nil {
+		return err
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	encoder := json.NewEncoder(out)
+	first := true
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		record := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			record[column.Name()] = jsonValue(values[i], column.DatabaseTypeName())
+		}
+		if !first {
+			if _, err := io.WriteString(out, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, "]\n")
+	return err
+}
+
+// jsonValue pretvara vrednost iz drajvera u JSON-prijateljski tip
+func jsonValue(value interface{}, dbType string) interface{} {
+	raw, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+	text := string(raw)
+	if strings.Contains(dbType, "DECIMAL") || strings.Contains(dbType, "FLOAT") || strings.Contains(dbType, "DOUBLE") {
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number
+		}
+	}
+	return text
+}
+
+// RequestAccountDeletion zakazuje brisanje naloga posle perioda odlaganja.
+// Zahteva ponovni unos lozinke.
+func RequestAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.AccountDeletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Password == "" {
+		utils.JSONError(w, "Password is required to delete the account", http.StatusBadRequest)
+		return
+	}
+
+	// Ponovna autentifikacija pre brisanja
+	var passwordHash sql.NullString
+	err := utils.DB.QueryRow("SELECT password FROM users WHERE id = ?", userID).Scan(&passwordHash)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching user for deletion", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !passwordHash.Valid || !auth.CheckPassword(req.Password, passwordHash.String) {
+		utils.JSONError(w, "Invalid password", http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	scheduledFor := now.Add(settings.accountDeletionGrace)
+	_, err = utils.DB.Exec(
+		"UPDATE users SET deletion_requested_at = ?, deletion_scheduled_for = ? WHERE id = ?",
+		now, scheduledFor, userID,
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error scheduling account deletion", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "account deletion scheduled", "scheduled_for", scheduledFor.Format(time.RFC3339))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(models.AccountDeletionStatus{
+		DeletionRequestedAt:  &now,
+		DeletionScheduledFor: &scheduledFor,
+	})
+}
+
+// CancelAccountDeletion otkazuje zakazano brisanje naloga
+func CancelAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	result, err := utils.DB.Exec(
+		"UPDATE users SET deletion_requested_at = NULL, deletion_scheduled_for = NULL WHERE id = ? AND deletion_scheduled_for IS NOT NULL",
+		userID,
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error cancelling account deletion", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.JSONError(w, "No account deletion is scheduled", http.StatusNotFound)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "account deletion cancelled")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.AccountDeletionStatus{})
+}
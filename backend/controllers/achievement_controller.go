@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+// achievementStats su zbirne vrednosti na osnovu kojih se procenjuju pravila
+type achievementStats struct {
+	workouts      int
+	minutes       int
+	distanceKm    float64
+	longestStreak int
+	weighIns      int
+	weightLostKg  float64
+	goalsAchieved int
+}
+
+// achievementRule je pravilo za otključavanje dostignuća: dostignuće je osvojeno
+// kada metric dostigne target
+type achievementRule struct {
+	code        string
+	name        string
+	description string
+	target      float64
+	metric      func(s achievementStats) float64
+}
+
+func workoutCount(s achievementStats) float64  { return float64(s.workouts) }
+func longestStreak(s achievementStats) float64 { return float64(s.longestStreak) }
+
+// achievementRules je katalog dostignuća. Kod pravila se čuva u bazi, pa se
+// postojeći kodovi ne menjaju; nova pravila se samo dodaju.
+var achievementRules = []achievementRule{
+	{"first_workout", "First Step", "Log your first workout", 1, workoutCount},
+	{"workouts_10", "Getting Started", "Log 10 workouts", 10, workoutCount},
+	{"workouts_50", "Regular", "Log 50 workouts", 50, workoutCount},
+	{"workouts_100", "Centurion", "Log 100 workouts", 100, workoutCount},
+	{"streak_7", "One Week Strong", "Work out 7 days in a row", 7, longestStreak},
+	{"streak_30", "Unstoppable", "Work out 30 days in a row", 30, longestStreak},
+	{"hours_50", "Fifty Hours", "Train for 50 hours in total", 50 * 60, func(s achievementStats) float64 { return float64(s.minutes) }},
+	{"distance_100km", "Road Runner", "Cover 100 km across all workouts", 100, func(s achievementStats) float64 { return s.distanceKm }},
+	{"first_weigh_in", "Baseline", "Record your first progress entry", 1, func(s achievementStats) float64 { return float64(s.weighIns) }},
+	{"weight_lost_5kg", "Lighter by Five", "Lose 5 kg since your first progress entry", 5, func(s achievementStats) float64 { return s.weightLostKg }},
+	{"goal_achieved", "Goal Getter", "Achieve a goal you set", 1, func(s achievementStats) float64 { return float64(s.goalsAchieved) }},
+}
+
+// GetAchievements vraća osvojena i zaključana dostignuća sa napretkom ka svakom
+func GetAchievements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	achievements, err := evaluateAchievements(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error evaluating achievements", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := models.AchievementsResponse{Earned: []models.Achievement{}, Locked: []models.Achievement{}}
+	for _, achievement := range achievements {
+		if achievement.Earned {
+			response.Earned = append(response.Earned, achievement)
+		} else {
+			response.Locked = append(response.Locked, achievement)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// refreshAchievements procenjuje pravila posle promene podataka korisnika.
+// Greška ne sme da obori zahtev koji je već uspeo, pa se samo loguje.
+func refreshAchievements(ctx context.Context, userID int) {
+	if _, err := evaluateAchievements(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "error evaluating achievements", "error", err)
+	}
+}
+
+// evaluateAchievements računa stanje svih pravila i upisuje novo otključana dostignuća.
+// Jednom otključano dostignuće ostaje osvojeno i ako se podaci kasnije obrišu.
+func evaluateAchievements(ctx context.Context, userID int) ([]models.Achievement, error) {
+	unlocked, err := loadUnlockedAchievements(userID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := loadAchievementStats(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	achievements := make([]models.Achievement, 0, len(achievementRules))
+	for _, rule := range achievementRules {
+		current := rule.metric(stats)
+		achievement := models.Achievement{
+			Code:        rule.code,
+			Name:        rule.name,
+			Description: rule.description,
+			Current:     math.Round(current*100) / 100,
+			Target:      rule.target,
+			Percent:     math.Round(math.Min(100, current/rule.target*100)*10) / 10,
+		}
+
+		if unlockedAt, ok := unlocked[rule.code]; ok {
+			achievement.Earned, achievement.UnlockedAt = true, &unlockedAt
+		} else if current >= rule.target {
+			// INSERT IGNORE štiti od duplikata kada se pravila procenjuju paralelno
+			result, err := utils.DB.Exec(
+				"INSERT IGNORE INTO user_achievements (user_id, code, unlocked_at) VALUES (?, ?, ?)",
+				userID, rule.code, now,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unlock achievement %s: %w", rule.code, err)
+			}
+			unlockedAt := now
+			if affected, _ := result.RowsAffected(); affected > 0 {
+				slog.InfoContext(ctx, "achievement unlocked", "achievement", rule.code)
+			} else {
+				// Paralelna procena je već upisala dostignuće; vraća se sačuvano vreme
+				err := utils.DB.QueryRow(
+					"SELECT unlocked_at FROM user_achievements WHERE user_id = ? AND code = ?",
+					userID, rule.code,
+				).Scan(&unlockedAt)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load achievement %s: %w", rule.code, err)
+				}
+			}
+			achievement.Earned, achievement.UnlockedAt = true, &unlockedAt
+		}
+		if achievement.Earned {
+			achievement.Percent = 100
+		}
+		achievements = append(achievements, achievement)
+	}
+	return achievements, nil
+}
+
+// loadUnlockedAchievements vraća već otključana dostignuća kao mapu kod -> vreme
+func loadUnlockedAchievements(userID int) (map[string]time.Time, error) {
+	rows, err := utils.DB.Query("SELECT code, unlocked_at FROM user_achievements WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load achievements: %w", err)
+	}
+	defer rows.Close()
+
+	unlocked := make(map[string]time.Time)
+	for rows.Next() {
+		var code string
+		var unlockedAt time.Time
+		if err := rows.Scan(&code, &unlockedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan achievement: %w", err)
+		}
+		unlocked[code] = unlockedAt
+	}
+	return unlocked, rows.Err()
+}
+
+// loadAchievementStats sabira treninge, merenja i ostvarene ciljeve korisnika
+func loadAchievementStats(userID int) (achievementStats, error) {
+	var stats achievementStats
+	var firstWeight, latestWeight *float64
+	err := utils.DB.QueryRow(
+		"SELECT "+
+			"(SELECT COUNT(*) FROM workouts WHERE user_id = u.id), "+
+			"(SELECT COALESCE(SUM(duration), 0) FROM workouts WHERE user_id = u.id), "+
+			"(SELECT COALESCE(SUM(distance_km), 0) FROM workouts WHERE user_id = u.id), "+
+			"(SELECT COUNT(*) FROM progress WHERE user_id = u.id), "+
+			"(SELECT weight FROM progress WHERE user_id = u.id ORDER BY progress_date, id LIMIT 1), "+
+			"(SELECT weight FROM progress WHERE user_id = u.id ORDER BY progress_date DESC, id DESC LIMIT 1), "+
+			"(SELECT COUNT(*) FROM user_goals WHERE user_id = u.id AND status = ?) "+
+			"FROM users u WHERE u.id = ?",
+		models.GoalStatusAchieved, userID,
+	).Scan(&stats.workouts, &stats.minutes, &stats.distanceKm, &stats.weighIns, &firstWeight, &latestWeight, &stats.goalsAchieved)
+	if err != nil {
+		return stats, fmt.Errorf("failed to load achievement stats: %w", err)
+	}
+	if firstWeight != nil && latestWeight != nil {
+		stats.weightLostKg = math.Max(0, *firstWeight-*latestWeight)
+	}
+
+	today := truncateToDay(time.Now())
+	days, err := loadTrainingDays(userID, today)
+	if err != nil {
+		return stats, fmt.Errorf("failed to load training days: %w", err)
+	}
+	_, stats.longestStreak = dailyStreaks(days, today)
+	return stats, nil
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"backend/models"
+	"backend/utils"
+)
+
+// defaultBodyWeightKg se koristi za procenu kalorija kada korisnik nema unetu težinu
+const defaultBodyWeightKg = 70.0
+
+var (
+	errUnknownActivity  = errors.New("unknown activity_type")
+	errCaloriesRequired = errors.New("calories_burned is required when activity_type is not set")
+)
+
+// GetActivities vraća katalog aktivnosti sa MET vrednostima
+func GetActivities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := utils.DB.Query("SELECT code, name, category, met FROM activity_types ORDER BY category, name")
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error querying activity types", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	activities := []models.ActivityType{}
+	for rows.Next() {
+		var activity models.ActivityType
+		if err := rows.Scan(&activity.Code, &activity.Name, &activity.Category, &activity.MET); err != nil {
+			slog.ErrorContext(r.Context(), "error scanning activity type row", "error", err)
+			continue
+		}
+		activities = append(activities, activity)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(r.Context(), "error iterating activity type rows", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(activities)
+}
+
+// resolveCaloriesBurned vraća kalorije iz zahteva ako su zadate (ručni unos),
+// a u suprotnom ih procenjuje iz MET vrednosti aktivnosti, trajanja i težine korisnika
+func resolveCaloriesBurned(userID int, req models.WorkoutRequest) (float64, string, error) {
+	if req.ActivityType != "" {
+		if _, err := activityMET(req.ActivityType); err != nil {
+			return 0, "", err
+		}
+	}
+
+	if req.CaloriesBurned != nil {
+		return *req.CaloriesBurned, models.CaloriesMethodManual, nil
+	}
+	if req.ActivityType == "" {
+		return 0, "", errCaloriesRequired
+	}
+
+	calories, err := estimateCaloriesBurned(userID, req.ActivityType, float64(req.Duration))
+	if err != nil {
+		return 0, "", err
+	}
+	return calories, models.CaloriesMethodMET, nil
+}
+
+// estimateCaloriesBurned računa potrošene kalorije po ACSM formuli:
+// kcal/min = MET * 3.5 * težina(kg) / 200
+func estimateCaloriesBurned(userID int, activityCode string, minutes float64) (float64, error) {
+	met, err := activityMET(activityCode)
+	if err != nil {
+		return 0, err
+	}
+	weight, err := latestWeight(userID)
+	if err != nil {
+		return 0, err
+	}
+	return utils.CaloriesFromMET(met, weight, minutes), nil
+}
+
+// activityMET vraća MET vrednost aktivnosti iz kataloga
+func activityMET(code string) (float64, error) {
+	var met float64
+	err := utils.DB.QueryRow("SELECT met FROM activity_types WHERE code = ?", code).Scan(&met)
+	if err == sql.ErrNoRows {
+		return 0, errUnknownActivity
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load activity type: %w", err)
+	}
+	return met, nil
+}
+
+// latestWeight vraća poslednju izmerenu težinu korisnika iz progress tabele,
+// zatim težinu sa profila, a ako ni ona nije uneta podrazumevanu vrednost
+func latestWeight(userID int) (float64, error) {
+	var weight sql.NullFloat64
+	err := utils.DB.QueryRow(
+		"SELECT weight FROM progress WHERE user_id = ? ORDER BY progress_date DESC, id DESC LIMIT 1",
+		userID,
+	).Scan(&weight)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to load latest weight: %w", err)
+	}
+	if weight.Valid && weight.Float64 > 0 {
+		return weight.Float64, nil
+	}
+
+	err = utils.DB.QueryRow("SELECT weight FROM users WHERE id = ?", userID).Scan(&weight)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to load profile weight: %w", err)
+	}
+	if weight.Valid && weight.Float64 > 0 {
+		return weight.Float64, nil
+	}
+
+	return defaultBodyWeightKg, nil
+}
+
+// caloriesErrorStatus mapira grešku iz resolveCaloriesBurned na HTTP status
+func caloriesErrorStatus(err error) int {
+	if errors.Is(err, errUnknownActivity) || errors.Is(err, errCaloriesRequired) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"backend/models"
+	"backend/utils"
+)
+
+// maxSampleGapSeconds ograničava koliko vremena jedan uzorak pulsa "pokriva"
+// kada se računa vreme u zonama, da pauze u snimanju ne bi naduvale rezultat
+const maxSampleGapSeconds = 30
+
+// heartRateZoneBounds su granice zona kao procenat maksimalnog pulsa
+var heartRateZoneBounds = []float64{0.50, 0.60, 0.70, 0.80, 0.90, 1.00}
+
+var errInvalidHeartRate = errors.New("heart rate values must be between 20 and 250 bpm")
+
+// validateCardioRequest proverava kardio polja zahteva i sortira uzorke pulsa
+func validateCardioRequest(req *models.WorkoutRequest) error {
+	if req.DistanceKm != nil && *req.DistanceKm < 0 {
+		return errors.New("distance_km must not be negative")
+	}
+	if req.ElevationGainM != nil && *req.ElevationGainM < 0 {
+		return errors.New("elevation_gain_m must not be negative")
+	}
+	for _, hr := range []*int{req.AvgHeartRate, req.MaxHeartRate} {
+		if hr != nil && !validBPM(*hr) {
+			return errInvalidHeartRate
+		}
+	}
+	for _, sample := range req.HeartRateSamples {
+		if !validBPM(sample.BPM) {
+			return errInvalidHeartRate
+		}
+		if sample.OffsetSeconds < 0 {
+			return errors.New("heart rate sample offset_seconds must not be negative")
+		}
+	}
+	sort.SliceStable(req.HeartRateSamples, func(i, j int) bool {
+		return req.HeartRateSamples[i].OffsetSeconds < req.HeartRateSamples[j].OffsetSeconds
+	})
+
+	// Prosečan i maksimalni puls se računaju iz uzoraka kada postoje
+	if len(req.HeartRateSamples) > 0 {
+		avg, max := heartRateSummary(req.HeartRateSamples)
+		req.AvgHeartRate = &avg
+		req.MaxHeartRate = &max
+	}
+	return nil
+}
+
+func validBPM(bpm int) bool {
+	return bpm >= 20 && bpm <= 250
+}
+
+// heartRateSummary vraća prosečan (vremenski ponderisan) i maksimalni puls
+func heartRateSummary(samples []models.HeartRateSample) (int, int) {
+	var weighted, total float64
+	max := 0
+	for i, sample := range samples {
+		if sample.BPM > max {
+			max = sample.BPM
+		}
+		weight := float64(sampleDuration(samples, i))
+		if weight == 0 {
+			weight = 1
+		}
+		weighted += float64(sample.BPM) * weight
+		total += weight
+	}
+	return int(math.Round(weighted / total)), max
+}
+
+// sampleDuration vraća broj sekundi koje uzorak pokriva (do sledećeg uzorka)
+func sampleDuration(samples []models.HeartRateSample, i int) int {
+	if i+1 >= len(samples) {
+		return 1
+	}
+	gap := samples[i+1].OffsetSeconds - samples[i].OffsetSeconds
+	if gap > maxSampleGapSeconds {
+		return maxSampleGapSeconds
+	}
+	return gap
+}
+
+// computeCardioMetrics računa tempo, brzinu i vreme u zonama pulsa za trening
+func computeCardioMetrics(workout models.Workout, samples []models.HeartRateSample, userMaxHR *int) *models.CardioMetrics {
+	hasDistance := workout.DistanceKm != nil && *workout.DistanceKm > 0
+	if !hasDistance && workout.AvgHeartRate == nil && len(samples) == 0 {
+		return nil
+	}
+
+	metrics := &models.CardioMetrics{
+		AvgHeartRate: workout.AvgHeartRate,
+		MaxHeartRate: workout.MaxHeartRate,
+	}
+
+	if hasDistance && workout.Duration > 0 {
+		seconds := float64(workout.Duration*60) / *workout.DistanceKm
+		speed := math.Round(*workout.DistanceKm/(float64(workout.Duration)/60)*100) / 100
+		seconds = math.Round(seconds)
+		metrics.AvgPaceSecondsPerKm = &seconds
+		metrics.AvgPace = formatPace(seconds)
+		metrics.AvgSpeedKmh = &speed
+	}
+
+	if len(samples) > 0 {
+		avg, max := heartRateSummary(samples)
+		metrics.AvgHeartRate = &avg
+		metrics.MaxHeartRate = &max
+		if userMaxHR != nil && *userMaxHR > 0 {
+			metrics.UserMaxHeartRate = userMaxHR
+			metrics.HeartRateZones = heartRateZones(samples, *userMaxHR)
+		}
+	}
+
+	return metrics
+}
+
+// heartRateZones raspoređuje vreme treninga u pet zona pulsa
+func heartRateZones(samples []models.HeartRateSample, maxHR int) []models.HeartRateZone {
+	zones := make([]models.HeartRateZone, len(heartRateZoneBounds)-1)
+	for i := range zones {
+		zones[i] = models.HeartRateZone{
+			Zone:   i + 1,
+			MinBPM: int(math.Round(heartRateZoneBounds[i] * float64(maxHR))),
+			MaxBPM: int(math.Round(heartRateZoneBounds[i+1]*float64(maxHR))) - 1,
+		}
+	}
+	zones[len(zones)-1].MaxBPM = maxHR
+
+	for i, sample := range samples {
+		zone := -1
+		for z := range zones {
+			if sample.BPM >= zones[z].MinBPM {
+				zone = z
+			}
+		}
+		if zone >= 0 {
+			zones[zone].Seconds += sampleDuration(samples, i)
+		}
+	}
+	return zones
+}
+
+// formatPace formatira tempo u sekundama po km kao m:ss
+func formatPace(secondsPerKm float64) string {
+	total := int(math.Round(secondsPerKm))
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// userMaxHeartRate vraća maksimalni puls sa profila korisnika, ako je unet
+func userMaxHeartRate(userID int) (*int, error) {
+	var maxHR sql.NullInt64
+	err := utils.DB.QueryRow("SELECT max_heart_rate FROM users WHERE id = ?", userID).Scan(&maxHR)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to load user max heart rate: %w", err)
+	}
+	if !maxHR.Valid {
+		return nil, nil
+	}
+	value := int(maxHR.Int64)
+	return &value, nil
+}
+
+// loadHeartRateSamples učitava uzorke pulsa za sve treninge korisnika u jednom upitu
+func loadHeartRateSamples(userID int, workoutIDs []int) (map[int][]models.HeartRateSample, error) {
+	samples := make(map[int][]models.HeartRateSample)
+	if len(workoutIDs) == 0 {
+		return samples, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(workoutIDs)), ",")
+	args := []interface{}{userID}
+	for _, id := range workoutIDs {
+		args = append(args, id)
+	}
+
+	rows, err := utils.DB.Query(
+		"SELECT s.workout_id, s.offset_seconds, s.bpm FROM workout_heart_rate_samples s "+
+			"JOIN workouts w ON w.id = s.workout_id WHERE w.user_id = ? AND s.workout_id IN ("+placeholders+") "+
+			"ORDER BY s.workout_id, s.offset_seconds",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query heart rate samples: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var workoutID int
+		var sample models.HeartRateSample
+		if err := rows.Scan(&workoutID, &sample.OffsetSeconds, &sample.BPM); err != nil {
+			return nil, fmt.Errorf("failed to scan heart rate sample: %w", err)
+		}
+		samples[workoutID] = append(samples[workoutID], sample)
+	}
+	return samples, rows.Err()
+}
+
+// attachCardioMetrics dodaje izvedene kardio metrike svakom treningu iz liste
+func attachCardioMetrics(userID int, workouts []models.Workout) error {
+	if len(workouts) == 0 {
+		return nil
+	}
+
+	userMaxHR, err := userMaxHeartRate(userID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(workouts))
+	for _, workout := range workouts {
+		ids = append(ids, workout.ID)
+	}
+	samples, err := loadHeartRateSamples(userID, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range workouts {
+		workouts[i].Cardio = computeCardioMetrics(workouts[i], samples[workouts[i].ID], userMaxHR)
+	}
+	return nil
+}
+
+// replaceHeartRateSamples briše postojeće i upisuje nove uzorke pulsa za trening
+func replaceHeartRateSamples(tx *sql.Tx, workoutID int64, samples []models.HeartRateSample) error {
+	if _, err := tx.Exec("DELETE FROM workout_heart_rate_samples WHERE workout_id = ?", workoutID); err != nil {
+		return fmt.Errorf("failed to delete heart rate samples: %w", err)
+	}
+
+	// Upis u grupama da bi se izbegao prevelik broj parametara po upitu
+	const batchSize = 500
+	for start := 0; start < len(samples); start += batchSize {
+		end := start + batchSize
+		if end > len(samples) {
+			end = len(samples)
+		}
+		batch := samples[start:end]
+
+		placeholders := strings.TrimSuffix(strings.Repeat("(?, ?, ?),", len(batch)), ",")
+		args := make([]interface{}, 0, len(batch)*3)
+		for _, sample := range batch {
+			args = append(args, workoutID, sample.OffsetSeconds, sample.BPM)
+		}
+		if _, err := tx.Exec("INSERT INTO workout_heart_rate_samples (workout_id, offset_seconds, bpm) VALUES "+placeholders, args...); err != nil {
+			return fmt.Errorf("failed to insert heart rate samples: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"time"
+
+	"backend/config"
+	"backend/middleware"
+)
+
+// settings su podešavanja kontrolera iz konfiguracije; postavlja ih Configure pri pokretanju
+var settings = struct {
+	accountDeletionGrace time.Duration           // period u kome korisnik može da otkaže brisanje naloga
+	healthCheckTimeout   time.Duration           // rok za svaku proveru u /health/ready
+	healthCheckFood      bool                    // da li /health/ready proverava Open Food Facts
+	lockoutThreshold     int                     // broj uzastopnih neuspelih prijava pre zaključavanja naloga
+	lockoutBase          time.Duration           // trajanje prvog zaključavanja
+	lockoutMax           time.Duration           // najduže zaključavanje
+	loginLimiter         *middleware.RateLimiter // pokušaji prijave po nalogu, nezavisno od IP adrese
+	totpIssuer           string                  // naziv aplikacije u otpauth URI-ju
+}{
+	accountDeletionGrace: 30 * 24 * time.Hour,
+	healthCheckTimeout:   2 * time.Second,
+	lockoutThreshold:     5,
+	lockoutBase:          time.Minute,
+	lockoutMax:           time.Hour,
+	totpIssuer:           "Fitness App",
+}
+
+// Configure prenosi kontrolerima podešavanja iz konfiguracije
+func Configure(cfg *config.Config) {
+	settings.accountDeletionGrace = cfg.AccountDeletionGrace
+	settings.healthCheckTimeout = cfg.HealthCheckTimeout
+	settings.healthCheckFood = cfg.HealthCheckFood
+	settings.lockoutThreshold = cfg.RateLimit.LockoutThreshold
+	settings.lockoutBase = cfg.RateLimit.LockoutBase
+	settings.lockoutMax = cfg.RateLimit.LockoutMax
+	settings.loginLimiter = middleware.NewRateLimiter("login", cfg.RateLimit.Auth)
+	settings.totpIssuer = cfg.TOTPIssuer
+}
@@ -0,0 +1,460 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/importers"
+	"backend/metrics"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+const (
+	// maxCSVImportRows ograničava broj redova u jednom uvozu
+	maxCSVImportRows = 20000
+	// maxReportedCSVErrors ograničava broj grešaka u izveštaju
+	maxReportedCSVErrors = 200
+	// maxCSVDurationMinutes je najduže trajanje jednog treninga (24h)
+	maxCSVDurationMinutes = 1440
+)
+
+// csvImportRow je validiran red spreman za upis
+type csvImportRow struct {
+	fingerprint string
+	args        []interface{}
+}
+
+// csvImportSpec opisuje kako se CSV redovi jednog entiteta validiraju i upisuju
+type csvImportSpec struct {
+	table  string
+	fields []string
+	// aliases su nazivi kolona koje piše CSV izvoz, da bi se izvezen fajl mogao
+	// uvesti bez mapiranja
+	aliases map[string]string
+	// insert prima user_id, zatim args iz reda i na kraju otisak. Red čiji otisak
+	// već postoji ne menja ništa i ne broji se kao upisan (paralelni uvoz istog fajla).
+	insert string
+	// prepare učitava podatke potrebne za validaciju (npr. katalog aktivnosti)
+	prepare func(userID int) (func(values map[string]string) ([]interface{}, string, []models.CSVImportRowError), error)
+}
+
+var csvImportSpecs = map[string]csvImportSpec{
+	"workouts": {
+		table:   "workouts",
+		fields:  []string{"workout_date", "name", "description", "activity_type", "duration", "calories_burned", "distance_km", "elevation_gain_m", "avg_heart_rate", "max_heart_rate"},
+		aliases: map[string]string{"duration": "duration_min"},
+		insert:  "INSERT INTO workouts (user_id, workout_date, name, description, activity_type, duration, calories_burned, calories_method, distance_km, elevation_gain_m, avg_heart_rate, max_heart_rate, source, import_fingerprint) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'csv', ?) ON DUPLICATE KEY UPDATE import_fingerprint = import_fingerprint",
+		prepare: func(userID int) (func(map[string]string) ([]interface{}, string, []models.CSVImportRowError), error) {
+			mets, err := utils.LoadActivityMETs()
+			if err != nil {
+				return nil, err
+			}
+			weight, err := latestWeight(userID)
+			if err != nil {
+				return nil, err
+			}
+			return func(values map[string]string) ([]interface{}, string, []models.CSVImportRowError) {
+				return parseWorkoutCSVRow(values, mets, weight)
+			}, nil
+		},
+	},
+	"progress": {
+		table:   "progress",
+		fields:  []string{"progress_date", "weight", "body_fat", "muscle_mass", "notes"},
+		aliases: map[string]string{"weight": "weight_kg", "body_fat": "body_fat_pct", "muscle_mass": "muscle_mass_kg"},
+		insert:  "INSERT INTO progress (user_id, progress_date, weight, body_fat, muscle_mass, notes, import_fingerprint) VALUES (?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE import_fingerprint = import_fingerprint",
+		prepare: func(int) (func(map[string]string) ([]interface{}, string, []models.CSVImportRowError), error) {
+			return parseProgressCSVRow, nil
+		},
+	},
+}
+
+// ImportCSV uvozi istorijske treninge ili unose napretka iz CSV fajla.
+// Multipart polja: file, entity (workouts|progress), mapping (JSON polje -> kolona)
+// i dry_run=true za proveru bez upisa. Ako bilo koji red nije validan, ništa se ne upisuje.
+func ImportCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		utils.JSONError(w, "Invalid upload. Send the file as multipart form field 'file' (max 25 MB)", http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		utils.JSONError(w, "Missing file field 'file'", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	entity := r.FormValue("entity")
+	spec, ok := csvImportSpecs[entity]
+	if !ok {
+		utils.JSONError(w, "entity must be 'workouts' or 'progress'", http.StatusBadRequest)
+		return
+	}
+
+	mapping := map[string]string{}
+	if raw := r.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			utils.JSONError(w, "mapping must be a JSON object of field -> CSV column", http.StatusBadRequest)
+			return
+		}
+	}
+
+	reader, err := importers.NewCSVReader(file, spec.fields, spec.aliases, mapping)
+	if err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	parseRow, err := spec.prepare(userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error preparing CSV import", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	existing, err := loadImportFingerprints(spec.table, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error loading import fingerprints", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	report := models.CSVImportReport{
+		Entity: entity,
+		DryRun: r.FormValue("dry_run") == "true",
+		Errors: []models.CSVImportRowError{},
+	}
+	errorCount := 0
+	var pending []csvImportRow
+
+	// Validacija svih redova pre upisa
+	for {
+		line, values, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			utils.JSONError(w, fmt.Sprintf("Invalid CSV: %v", err), http.StatusBadRequest)
+			return
+		}
+		report.TotalRows++
+		if report.TotalRows > maxCSVImportRows {
+			utils.JSONError(w, fmt.Sprintf("CSV file has more than %d rows, split it into smaller files", maxCSVImportRows), http.StatusBadRequest)
+			return
+		}
+
+		args, fingerprint, rowErrors := parseRow(values)
+		if len(rowErrors) > 0 {
+			errorCount += len(rowErrors)
+			for _, rowError := range rowErrors {
+				if len(report.Errors) < maxReportedCSVErrors {
+					rowError.Row = line
+					report.Errors = append(report.Errors, rowError)
+				}
+			}
+			continue
+		}
+
+		report.ValidRows++
+		if existing[fingerprint] {
+			report.SkippedDuplicates++
+			continue
+		}
+		existing[fingerprint] = true
+		pending = append(pending, csvImportRow{fingerprint: fingerprint, args: args})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if errorCount > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+	if report.DryRun {
+		report.Imported = len(pending)
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	// Svi redovi se upisuju u jednoj transakciji: ili sve ili ništa
+	imported, err := insertCSVRows(spec, userID, pending)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error importing CSV", "entity", entity, "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to import CSV: %v", err), http.StatusInternalServerError)
+		return
+	}
+	// Redove koje je u međuvremenu upisao paralelni uvoz baza preskače
+	report.Imported = imported
+	report.SkippedDuplicates += len(pending) - imported
+	if spec.table == "workouts" {
+		metrics.WorkoutsCreated.Add(float64(report.Imported), "csv")
+	}
+
+	slog.InfoContext(r.Context(), "imported CSV rows", "entity", entity, "imported", report.Imported, "skipped_duplicates", report.SkippedDuplicates)
+	if report.Imported > 0 {
+		refreshAchievements(r.Context(), userID)
+		refreshGoal(r.Context(), userID)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// insertCSVRows upisuje redove i vraća koliko ih je zaista upisano
+func insertCSVRows(spec csvImportSpec, userID int, rows []csvImportRow) (int, error) {
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(spec.insert)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, row := range rows {
+		args := append([]interface{}{userID}, row.args...)
+		args = append(args, row.fingerprint)
+		result, err := stmt.Exec(args...)
+		if err != nil {
+			return 0, err
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+			inserted++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// loadImportFingerprints učitava otiske već uvezenih redova korisnika
+func loadImportFingerprints(table string, userID int) (map[string]bool, error) {
+	rows, err := utils.DB.Query("SELECT import_fingerprint FROM "+table+" WHERE user_id = ? AND import_fingerprint IS NOT NULL", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fingerprints := make(map[string]bool)
+	for rows.Next() {
+		var fingerprint string
+		if err := rows.Scan(&fingerprint); err != nil {
+			return nil, err
+		}
+		fingerprints[fingerprint] = true
+	}
+	return fingerprints, rows.Err()
+}
+
+// rowValidator skuplja greške validacije jednog reda
+type rowValidator struct {
+	values map[string]string
+	errors []models.CSVImportRowError
+}
+
+func (v *rowValidator) fail(field, format string, args ...interface{}) {
+	v.errors = append(v.errors, models.CSVImportRowError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *rowValidator) date(field string) time.Time {
+	value := v.values[field]
+	if value == "" {
+		v.fail(field, "is required")
+		return time.Time{}
+	}
+	date, err := parseFlexibleDate(value)
+	if err != nil {
+		v.fail(field, "invalid date %q, use YYYY-MM-DD or DD.MM.YYYY", value)
+	}
+	return date
+}
+
+// number čita opcioni decimalni broj u opsegu [min, max]
+func (v *rowValidator) number(field string, min, max float64) *float64 {
+	value := v.values[field]
+	if value == "" {
+		return nil
+	}
+	number, err := parseDecimal(value)
+	if err != nil {
+		v.fail(field, "invalid number %q", value)
+		return nil
+	}
+	if number < min || number > max {
+		v.fail(field, "must be between %g and %g", min, max)
+		return nil
+	}
+	return &number
+}
+
+func (v *rowValidator) heartRate(field string) *int {
+	bpm := v.number(field, 20, 250)
+	if bpm == nil {
+		return nil
+	}
+	value := int(math.Round(*bpm))
+	return &value
+}
+
+func parseWorkoutCSVRow(values map[string]string, mets map[string]float64, weight float64) ([]interface{}, string, []models.CSVImportRowError) {
+	v := &rowValidator{values: values}
+
+	date := v.date("workout_date")
+
+	activityType := strings.ToLower(values["activity_type"])
+	if activityType != "" {
+		if _, ok := mets[activityType]; !ok {
+			v.fail("activity_type", "unknown activity type %q", activityType)
+			activityType = ""
+		}
+	}
+
+	name := csvUnescapeText(values["name"])
+	if name == "" && activityType != "" {
+		name = strings.ToUpper(activityType[:1]) + strings.ReplaceAll(activityType[1:], "_", " ")
+	}
+	if name == "" {
+		v.fail("name", "is required when activity_type is empty")
+	}
+
+	duration := 0
+	if values["duration"] == "" {
+		v.fail("duration", "is required")
+	} else if minutes, err := parseDurationMinutes(values["duration"]); err != nil || minutes <= 0 {
+		v.fail("duration", "invalid duration %q, use minutes or h:mm:ss", values["duration"])
+	} else if minutes > maxCSVDurationMinutes {
+		v.fail("duration", "must be at most %d minutes", maxCSVDurationMinutes)
+	} else {
+		duration = int(math.Max(1, math.Round(minutes)))
+	}
+
+	calories := v.number("calories_burned", 0, 20000)
+	method := models.CaloriesMethodManual
+	if calories == nil {
+		if activityType == "" {
+			v.fail("calories_burned", "is required when activity_type is empty")
+		} else if duration > 0 {
+			estimated := utils.CaloriesFromMET(mets[activityType], weight, float64(duration))
+			calories, method = &estimated, models.CaloriesMethodMET
+		}
+	}
+
+	distance := v.number("distance_km", 0, 10000)
+	elevation := v.number("elevation_gain_m", 0, 100000)
+	avgHR := v.heartRate("avg_heart_rate")
+	maxHR := v.heartRate("max_heart_rate")
+
+	if len(v.errors) > 0 {
+		return nil, "", v.errors
+	}
+
+	fingerprint := csvFingerprint("workout", date.Format("2006-01-02"), strings.ToLower(name), strconv.Itoa(duration), values["distance_km"])
+	return []interface{}{date, name, csvUnescapeText(values["description"]), nullableString(activityType), duration, *calories, method, distance, elevation, avgHR, maxHR}, fingerprint, nil
+}
+
+func parseProgressCSVRow(values map[string]string) ([]interface{}, string, []models.CSVImportRowError) {
+	v := &rowValidator{values: values}
+
+	date := v.date("progress_date")
+	weight := v.number("weight", 0.01, 999.99)
+	if values["weight"] == "" {
+		v.fail("weight", "is required")
+	}
+	bodyFat := v.number("body_fat", 0, 100)
+	muscleMass := v.number("muscle_mass", 0, 999.99)
+
+	if len(v.errors) > 0 {
+		return nil, "", v.errors
+	}
+
+	fingerprint := csvFingerprint("progress", date.Format("2006-01-02"), strconv.FormatFloat(*weight, 'f', 2, 64))
+	return []interface{}{date, *weight, bodyFat, muscleMass, csvUnescapeText(values["notes"])}, fingerprint, nil
+}
+
+// csvFingerprint pravi otisak reda od normalizovanih vrednosti ključnih polja
+func csvFingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte("csv|" + strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseFlexibleDate prihvata formate datuma koje najčešće izvoze tabelarni programi
+func parseFlexibleDate(value string) (time.Time, error) {
+	value = strings.TrimSuffix(value, ".")
+	for _, layout := range []string{"2006-01-02", "02.01.2006", "2.1.2006", "2006/01/02", time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date")
+}
+
+// parseDecimal prihvata i decimalni zarez (72,5). NaN i beskonačnost se odbijaju
+// jer ih ParseFloat prihvata, a baza ne.
+func parseDecimal(value string) (float64, error) {
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, errors.New("number must be finite")
+	}
+	return number, nil
+}
+
+// csvUnescapeText uklanja apostrof koji csvText dodaje ispred teksta nalik formuli
+func csvUnescapeText(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// parseDurationMinutes prihvata minute (45 ili 45,5), mm:ss ili h:mm:ss
+func parseDurationMinutes(value string) (float64, error) {
+	if !strings.Contains(value, ":") {
+		return parseDecimal(value)
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, errors.New("invalid duration")
+	}
+	var seconds float64
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, errors.New("invalid duration")
+		}
+		seconds = seconds*60 + float64(n)
+	}
+	return seconds / 60, nil
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+)
+
+func csvImportRequest(t *testing.T, userID int, entity, content string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("entity", entity)
+	file, err := form.CreateFormFile("file", entity+".csv")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	file.Write([]byte(content))
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/import/csv", &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	return r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, userID))
+}
+
+// Paralelni uvoz istog fajla: red koji je drugi zahtev upisao posle učitavanja
+// otisaka baza preskače, a izveštaj ga broji kao duplikat umesto greške 500
+func TestImportCSVCountsConcurrentDuplicates(t *testing.T) {
+	db := newFakeDB(t)
+	db.affected = func(statement fakeStatement) int64 {
+		if strings.HasPrefix(statement.query, "INSERT INTO progress") {
+			if date, ok := statement.args[1].(time.Time); ok && date.Day() == 2 {
+				return 0
+			}
+		}
+		return 1
+	}
+
+	w := httptest.NewRecorder()
+	ImportCSV(w, csvImportRequest(t, 1, "progress", "progress_date,weight\n2024-03-01,80.5\n2024-03-02,80.1\n"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var report models.CSVImportReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.ValidRows != 2 || report.Imported != 1 || report.SkippedDuplicates != 1 {
+		t.Fatalf("report = %+v, want 2 valid, 1 imported, 1 skipped", report)
+	}
+	insert, ok := db.executed("INSERT INTO progress")
+	if !ok || !strings.Contains(insert.query, "ON DUPLICATE KEY UPDATE") {
+		t.Fatalf("insert %q does not tolerate existing fingerprints", insert.query)
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+// Gruba procena dnevnih potreba: održavanje ~30 kcal po kg telesne težine,
+// uz deficit za mršavljenje i suficit za hipertrofiju
+const (
+	maintenanceKcalPerKg  = 30.0
+	weightLossDeficitKcal = 500.0
+	hypertrophySurplus    = 300.0
+)
+
+// dashboardQuery računa nedeljni zbir, težinu i profil u jednom upitu. Trenutni niz
+// se računa isto kao na /api/streaks (loadTrainingDays i dailyStreaks), da bi se
+// brojevi na dashboardu i stranici sa nizovima uvek slagali.
+const dashboardQuery = `
+SELECT
+	week.workouts, week.minutes, week.calories,
+	latest.weight, latest.progress_date,
+	(SELECT weight FROM progress WHERE user_id = u.id AND progress_date <= DATE_SUB(latest.progress_date, INTERVAL 7 DAY)
+		ORDER BY progress_date DESC, id DESC LIMIT 1) AS week_ago_weight,
+	u.goal, u.weight
+FROM users u
+CROSS JOIN (
+	SELECT COUNT(*) AS workouts, COALESCE(SUM(duration), 0) AS minutes, COALESCE(SUM(calories_burned), 0) AS calories
+	FROM workouts WHERE user_id = ? AND workout_date BETWEEN ? AND ?
+) week
+LEFT JOIN (
+	SELECT weight, progress_date FROM progress WHERE user_id = ? ORDER BY progress_date DESC, id DESC LIMIT 1
+) latest ON TRUE
+WHERE u.id = ?`
+
+// GetDashboard vraća sažetak tekuće nedelje: treninge, težinu, niz i kalorijski cilj
+func GetDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	today := truncateToDay(time.Now())
+	weekStart, weekEnd := weekBounds(today)
+
+	var dashboard models.Dashboard
+	var latestWeight, weekAgoWeight, profileWeight sql.NullFloat64
+	var latestDate sql.NullTime
+	var goal string
+	err := utils.DB.QueryRow(dashboardQuery,
+		userID, weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"),
+		userID,
+		userID,
+	).Scan(
+		&dashboard.WorkoutsThisWeek, &dashboard.MinutesThisWeek, &dashboard.CaloriesBurnedThisWeek,
+		&latestWeight, &latestDate, &weekAgoWeight,
+		&goal, &profileWeight,
+	)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error building dashboard", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	days, err := loadTrainingDays(userID, today)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching training days", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	dashboard.CurrentStreakDays, _ = dailyStreaks(days, today)
+
+	dashboard.WeekStart = weekStart.Format("2006-01-02")
+	dashboard.WeekEnd = weekEnd.Format("2006-01-02")
+	dashboard.CaloriesBurnedThisWeek = math.Round(dashboard.CaloriesBurnedThisWeek*100) / 100
+
+	weight := defaultBodyWeightKg
+	if profileWeight.Valid && profileWeight.Float64 > 0 {
+		weight = profileWeight.Float64
+	}
+	if latestWeight.Valid {
+		date := latestDate.Time.Format("2006-01-02")
+		dashboard.LatestWeight = &latestWeight.Float64
+		dashboard.LatestWeightDate = &date
+		weight = latestWeight.Float64
+		if weekAgoWeight.Valid {
+			change := math.Round((latestWeight.Float64-weekAgoWeight.Float64)*100) / 100
+			dashboard.WeightChangeVsLastWeek = &change
+		}
+	}
+	dashboard.DailyCalorieTarget = dailyCalorieTarget(goal, weight)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dashboard)
+}
+
+// weekBounds vraća ponedeljak i nedelju nedelje u kojoj je dati dan
+func weekBounds(day time.Time) (time.Time, time.Time) {
+	offset := (int(day.Weekday()) + 6) % 7
+	start := time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, day.Location())
+	return start, start.AddDate(0, 0, 6)
+}
+
+// dailyCalorieTarget procenjuje dnevni unos kalorija prema cilju i težini
+func dailyCalorieTarget(goal string, weightKg float64) float64 {
+	target := weightKg * maintenanceKcalPerKg
+	switch goal {
+	case "lose_weight":
+		target -= weightLossDeficitKcal
+	case "hypertrophy":
+		target += hypertrophySurplus
+	}
+	return math.Round(target)
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+)
+
+// Dashboard i /api/streaks računaju trenutni niz istim kodom
+func TestDashboardStreakMatchesStreaks(t *testing.T) {
+	today := truncateToDay(time.Now())
+	day := func(offset int) time.Time { return today.AddDate(0, 0, offset) }
+
+	db := newFakeDB(t)
+	db.on("FROM users u", []string{"workouts", "minutes", "calories", "weight", "progress_date", "week_ago_weight", "goal", "profile_weight"},
+		[]driver.Value{int64(2), int64(90), 640.0, nil, nil, nil, "lose_weight", 80.0})
+	db.on("GROUP BY workout_date", []string{"workout_date", "workouts", "minutes"},
+		[]driver.Value{day(-6), int64(1), int64(30)},
+		[]driver.Value{day(-2), int64(1), int64(45)},
+		[]driver.Value{day(-1), int64(2), int64(60)},
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/dashboard", nil)
+	r = r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, 1))
+	w := httptest.NewRecorder()
+	GetDashboard(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var dashboard models.Dashboard
+	if err := json.NewDecoder(w.Body).Decode(&dashboard); err != nil {
+		t.Fatalf("decode dashboard: %v", err)
+	}
+	// Niz koji se završio juče je i dalje trenutni
+	if dashboard.CurrentStreakDays != 2 {
+		t.Fatalf("current_streak_days = %d, want 2", dashboard.CurrentStreakDays)
+	}
+	if dashboard.DailyCalorieTarget != 1900 {
+		t.Fatalf("daily_calorie_target = %v, want 1900", dashboard.DailyCalorieTarget)
+	}
+}
@@ -4,11 +4,12 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
+	"backend/metrics"
 	"backend/middleware"
 	"backend/models"
 	"backend/utils"
@@ -16,46 +17,128 @@ import (
 
 // ========== WORKOUTS ==========
 
+// workoutColumns su kolone koje se čitaju pri svakom dohvatanju treninga
+const workoutColumns = "id, user_id, name, description, activity_type, duration, intensity, calories_burned, calories_method, distance_km, elevation_gain_m, avg_heart_rate, max_heart_rate, source, started_at, workout_date, created_at, updated_at"
+
+// rowScanner je zajednički interfejs za *sql.Row i *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWorkout čita jedan red iz workouts tabele u model
+func scanWorkout(row rowScanner) (models.Workout, error) {
+	var workout models.Workout
+	var description, activityType, source sql.NullString
+	var distance, elevation sql.NullFloat64
+	var intensity, avgHR, maxHR sql.NullInt64
+	var startedAt sql.NullTime
+	err := row.Scan(&workout.ID, &workout.UserID, &workout.Name, &description, &activityType, &workout.Duration, &intensity,
+		&workout.CaloriesBurned, &workout.CaloriesMethod, &distance, &elevation, &avgHR, &maxHR, &source, &startedAt,
+		&workout.WorkoutDate, &workout.CreatedAt, &workout.UpdatedAt)
+	if description.Valid {
+		workout.Description = description.String
+	}
+	if activityType.Valid {
+		workout.ActivityType = activityType.String
+	}
+	if intensity.Valid {
+		value := int(intensity.Int64)
+		workout.Intensity = &value
+	}
+	if distance.Valid {
+		workout.DistanceKm = &distance.Float64
+	}
+	if elevation.Valid {
+		workout.ElevationGainM = &elevation.Float64
+	}
+	if avgHR.Valid {
+		value := int(avgHR.Int64)
+		workout.AvgHeartRate = &value
+	}
+	if maxHR.Valid {
+		value := int(maxHR.Int64)
+		workout.MaxHeartRate = &value
+	}
+	if source.Valid {
+		workout.Source = source.String
+	}
+	if startedAt.Valid {
+		workout.StartedAt = &startedAt.Time
+	}
+	return workout, err
+}
+
+// fetchWorkout učitava jedan trening zajedno sa vežbama i izvedenim kardio metrikama
+func fetchWorkout(userID int, workoutID int64) (models.Workout, error) {
+	workout, err := scanWorkout(utils.DB.QueryRow("SELECT "+workoutColumns+" FROM workouts WHERE id = ?", workoutID))
+	if err != nil {
+		return workout, err
+	}
+	workouts := []models.Workout{workout}
+	if err := attachCardioMetrics(userID, workouts); err != nil {
+		return workout, err
+	}
+	if err := attachExercises(userID, workouts); err != nil {
+		return workout, err
+	}
+	return workouts[0], nil
+}
+
+// nullableString pretvara prazan string u NULL za upis u bazu
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
 func GetWorkouts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	userID := middleware.GetUserID(r)
 	if userID == 0 {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	rows, err := utils.DB.Query(
-		"SELECT id, user_id, name, description, duration, calories_burned, workout_date, created_at, updated_at FROM workouts WHERE user_id = ? ORDER BY workout_date DESC",
+		"SELECT "+workoutColumns+" FROM workouts WHERE user_id = ? ORDER BY workout_date DESC",
 		userID,
 	)
 	if err != nil {
-		log.Printf("❌ Error querying workouts: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error querying workouts", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
 	defer rows.Close()
 
 	var workouts []models.Workout
 	for rows.Next() {
-		var workout models.Workout
-		var description sql.NullString
-		if err := rows.Scan(&workout.ID, &workout.UserID, &workout.Name, &description, &workout.Duration, &workout.CaloriesBurned, &workout.WorkoutDate, &workout.CreatedAt, &workout.UpdatedAt); err != nil {
-			log.Printf("❌ Error scanning workout row: %v", err)
+		workout, err := scanWorkout(rows)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "error scanning workout row", "error", err)
 			continue
 		}
-		if description.Valid {
-			workout.Description = description.String
-		}
 		workouts = append(workouts, workout)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("❌ Error iterating workout rows: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error iterating workout rows", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := attachCardioMetrics(userID, workouts); err != nil {
+		slog.ErrorContext(r.Context(), "error computing cardio metrics", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := attachExercises(userID, workouts); err != nil {
+		slog.ErrorContext(r.Context(), "error loading workout exercises", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -65,13 +148,13 @@ func GetWorkouts(w http.ResponseWriter, r *http.Request) {
 
 func CreateWorkout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	userID := middleware.GetUserID(r)
 	if userID == 0 {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
@@ -79,54 +162,93 @@ func CreateWorkout(w http.ResponseWriter, r *http.Request) {
 	var userExists int
 	err := utils.DB.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", userID).Scan(&userExists)
 	if err != nil {
-		log.Printf("❌ Error checking if user exists: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error checking if user exists", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if userExists == 0 {
-		log.Printf("❌ User with ID %d does not exist in database", userID)
-		http.Error(w, "User not found. Please log in again.", http.StatusUnauthorized)
+		slog.WarnContext(r.Context(), "user from token does not exist in database")
+		utils.JSONError(w, "User not found. Please log in again.", http.StatusUnauthorized)
 		return
 	}
 
 	var req models.WorkoutRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	workoutDate, err := time.Parse("2006-01-02", req.WorkoutDate)
 	if err != nil {
-		http.Error(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("📝 Creating workout for user_id=%d: name=%s, date=%s", userID, req.Name, req.WorkoutDate)
+	if err := validateCardioRequest(&req); err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateStrengthRequest(req); err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	result, err := utils.DB.Exec(
-		"INSERT INTO workouts (user_id, name, description, duration, calories_burned, workout_date) VALUES (?, ?, ?, ?, ?, ?)",
-		userID, req.Name, req.Description, req.Duration, req.CaloriesBurned, workoutDate,
-	)
+	// Kalorije: ručni unos ima prednost, inače procena iz MET vrednosti
+	caloriesBurned, caloriesMethod, err := resolveCaloriesBurned(userID, req)
 	if err != nil {
-		log.Printf("❌ Error creating workout: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to create workout: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error resolving calories burned", "error", err)
+		utils.JSONError(w, err.Error(), caloriesErrorStatus(err))
 		return
 	}
 
-	workoutID, _ := result.LastInsertId()
-	var workout models.Workout
-	var description sql.NullString
-	err = utils.DB.QueryRow("SELECT id, user_id, name, description, duration, calories_burned, workout_date, created_at, updated_at FROM workouts WHERE id = ?", workoutID).Scan(
-		&workout.ID, &workout.UserID, &workout.Name, &description, &workout.Duration, &workout.CaloriesBurned, &workout.WorkoutDate, &workout.CreatedAt, &workout.UpdatedAt,
+	slog.DebugContext(r.Context(), "creating workout", "workout_date", req.WorkoutDate, "calories", caloriesBurned, "calories_method", caloriesMethod)
+
+	// Trening i uzorci pulsa se upisuju u jednoj transakciji
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error starting transaction", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO workouts (user_id, name, description, activity_type, duration, intensity, calories_burned, calories_method, distance_km, elevation_gain_m, avg_heart_rate, max_heart_rate, workout_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		userID, req.Name, req.Description, nullableString(req.ActivityType), req.Duration, req.Intensity, caloriesBurned, caloriesMethod,
+		req.DistanceKm, req.ElevationGainM, req.AvgHeartRate, req.MaxHeartRate, workoutDate,
 	)
 	if err != nil {
-		log.Printf("❌ Error fetching created workout: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to fetch created workout: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error creating workout", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to create workout: %v", err), http.StatusInternalServerError)
 		return
 	}
-	if description.Valid {
-		workout.Description = description.String
+
+	workoutID, _ := result.LastInsertId()
+	if err := replaceHeartRateSamples(tx, workoutID, req.HeartRateSamples); err != nil {
+		slog.ErrorContext(r.Context(), "error saving heart rate samples", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to create workout: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := replaceWorkoutExercises(tx, workoutID, req.Exercises); err != nil {
+		slog.ErrorContext(r.Context(), "error saving workout exercises", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to create workout: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		slog.ErrorContext(r.Context(), "error committing workout", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to create workout: %v", err), http.StatusInternalServerError)
+		return
+	}
+	metrics.WorkoutsCreated.Inc("manual")
+
+	workout, err := fetchWorkout(userID, workoutID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching created workout", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to fetch created workout: %v", err), http.StatusInternalServerError)
+		return
 	}
+	refreshAchievements(r.Context(), userID)
+	refreshGoal(r.Context(), userID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -135,7 +257,7 @@ func CreateWorkout(w http.ResponseWriter, r *http.Request) {
 
 func UpdateWorkout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -143,58 +265,219 @@ func UpdateWorkout(w http.ResponseWriter, r *http.Request) {
 	workoutID, _ := strconv.Atoi(r.URL.Query().Get("id"))
 
 	var ownerID int
-	if err := utils.DB.QueryRow("SELECT user_id FROM workouts WHERE id = ?", workoutID).Scan(&ownerID); err == sql.ErrNoRows {
-		http.Error(w, "Workout not found", http.StatusNotFound)
+	var stored storedWorkoutFields
+	if err := utils.DB.QueryRow(
+		"SELECT user_id, activity_type, duration, intensity, calories_burned, calories_method, distance_km, elevation_gain_m, avg_heart_rate, max_heart_rate FROM workouts WHERE id = ?",
+		workoutID,
+	).Scan(&ownerID, &stored.activityType, &stored.duration, &stored.intensity, &stored.caloriesBurned, &stored.caloriesMethod,
+		&stored.distanceKm, &stored.elevationGainM, &stored.avgHeartRate, &stored.maxHeartRate); err == sql.ErrNoRows {
+		utils.JSONError(w, "Workout not found", http.StatusNotFound)
 		return
 	} else if err != nil {
-		log.Printf("❌ Error checking workout ownership: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error checking workout ownership", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	} else if ownerID != userID {
-		http.Error(w, "Unauthorized", http.StatusForbidden)
+		utils.JSONError(w, "Unauthorized", http.StatusForbidden)
 		return
 	}
 
 	var req models.WorkoutRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	// Polja koja nisu poslata zadržavaju sačuvane vrednosti (npr. izmena naziva uvezenog treninga)
+	stored.mergeInto(&req)
 	workoutDate, err := time.Parse("2006-01-02", req.WorkoutDate)
 	if err != nil {
-		http.Error(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateCardioRequest(&req); err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateStrengthRequest(req); err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Ako uzorci nisu poslati, prosečan i maksimalni puls ostaju izvedeni iz postojećih uzoraka
+	if req.HeartRateSamples == nil && req.AvgHeartRate == nil {
+		existing, err := loadHeartRateSamples(userID, []int{workoutID})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "error loading heart rate samples", "error", err)
+			utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if samples := existing[workoutID]; len(samples) > 0 {
+			avg, max := heartRateSummary(samples)
+			req.AvgHeartRate = &avg
+			req.MaxHeartRate = &max
+		}
+	}
+
+	caloriesBurned, caloriesMethod, err := stored.resolveCalories(userID, req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error resolving calories burned", "error", err)
+		utils.JSONError(w, err.Error(), caloriesErrorStatus(err))
 		return
 	}
 
-	_, err = utils.DB.Exec("UPDATE workouts SET name = ?, description = ?, duration = ?, calories_burned = ?, workout_date = ? WHERE id = ?",
-		req.Name, req.Description, req.Duration, req.CaloriesBurned, workoutDate, workoutID)
+	tx, err := utils.DB.Begin()
 	if err != nil {
-		log.Printf("❌ Error updating workout: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to update workout: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error starting transaction", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
+	defer tx.Rollback()
 
-	var workout models.Workout
-	var description sql.NullString
-	err = utils.DB.QueryRow("SELECT id, user_id, name, description, duration, calories_burned, workout_date, created_at, updated_at FROM workouts WHERE id = ?", workoutID).Scan(
-		&workout.ID, &workout.UserID, &workout.Name, &description, &workout.Duration, &workout.CaloriesBurned, &workout.WorkoutDate, &workout.CreatedAt, &workout.UpdatedAt,
-	)
+	_, err = tx.Exec("UPDATE workouts SET name = ?, description = ?, activity_type = ?, duration = ?, intensity = ?, calories_burned = ?, calories_method = ?, distance_km = ?, elevation_gain_m = ?, avg_heart_rate = ?, max_heart_rate = ?, workout_date = ? WHERE id = ?",
+		req.Name, req.Description, nullableString(req.ActivityType), req.Duration, req.Intensity, caloriesBurned, caloriesMethod,
+		req.DistanceKm, req.ElevationGainM, req.AvgHeartRate, req.MaxHeartRate, workoutDate, workoutID)
 	if err != nil {
-		log.Printf("❌ Error fetching updated workout: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to fetch updated workout: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error updating workout", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to update workout: %v", err), http.StatusInternalServerError)
 		return
 	}
-	if description.Valid {
-		workout.Description = description.String
+
+	// Uzorci pulsa se menjaju samo ako su poslati u zahtevu
+	if req.HeartRateSamples != nil {
+		if err := replaceHeartRateSamples(tx, int64(workoutID), req.HeartRateSamples); err != nil {
+			slog.ErrorContext(r.Context(), "error saving heart rate samples", "error", err)
+			utils.JSONError(w, fmt.Sprintf("Failed to update workout: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	// Vežbe se takođe menjaju samo ako su poslate
+	if req.Exercises != nil {
+		if err := replaceWorkoutExercises(tx, int64(workoutID), req.Exercises); err != nil {
+			slog.ErrorContext(r.Context(), "error saving workout exercises", "error", err)
+			utils.JSONError(w, fmt.Sprintf("Failed to update workout: %v", err), http.StatusInternalServerError)
+			return
+		}
 	}
+	if err := tx.Commit(); err != nil {
+		slog.ErrorContext(r.Context(), "error committing workout update", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to update workout: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	workout, err := fetchWorkout(userID, int64(workoutID))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching updated workout", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to fetch updated workout: %v", err), http.StatusInternalServerError)
+		return
+	}
+	refreshAchievements(r.Context(), userID)
+	refreshGoal(r.Context(), userID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(workout)
 }
 
+// storedWorkoutFields su opcione kolone treninga koje izmena zadržava ako nisu poslate
+type storedWorkoutFields struct {
+	activityType               sql.NullString
+	duration                   int
+	intensity                  sql.NullInt64
+	caloriesBurned             float64
+	caloriesMethod             string
+	distanceKm, elevationGainM sql.NullFloat64
+	avgHeartRate, maxHeartRate sql.NullInt64
+}
+
+// resolveCalories vraća kalorije za izmenjeni trening. Bez calories_burned u zahtevu
+// zadržavaju se sačuvana vrednost i metod (npr. kalorije sa uređaja pri promeni naziva);
+// ponovo se procenjuju samo MET kalorije kada se promene trajanje ili aktivnost.
+func (s storedWorkoutFields) resolveCalories(userID int, req models.WorkoutRequest) (float64, string, error) {
+	changed := req.Duration != s.duration || req.ActivityType != s.activityType.String
+	if req.CaloriesBurned != nil || (s.caloriesMethod == models.CaloriesMethodMET && changed) {
+		return resolveCaloriesBurned(userID, req)
+	}
+	if req.ActivityType != "" && req.ActivityType != s.activityType.String {
+		if _, err := activityMET(req.ActivityType); err != nil {
+			return 0, "", err
+		}
+	}
+	return s.caloriesBurned, s.caloriesMethod, nil
+}
+
+// mergeInto popunjava izostavljena polja zahteva sačuvanim vrednostima. Prosečan i
+// maksimalni puls se preuzimaju samo ako uzorci nisu poslati, jer se inače računaju iz njih.
+func (s storedWorkoutFields) mergeInto(req *models.WorkoutRequest) {
+	if req.ActivityType == "" && s.activityType.Valid {
+		req.ActivityType = s.activityType.String
+	}
+	if req.Intensity == nil {
+		req.Intensity = nullIntPtr(s.intensity)
+	}
+	if req.DistanceKm == nil {
+		req.DistanceKm = nullFloatPtr(s.distanceKm)
+	}
+	if req.ElevationGainM == nil {
+		req.ElevationGainM = nullFloatPtr(s.elevationGainM)
+	}
+	if req.HeartRateSamples == nil && req.AvgHeartRate == nil {
+		req.AvgHeartRate = nullIntPtr(s.avgHeartRate)
+		if req.MaxHeartRate == nil {
+			req.MaxHeartRate = nullIntPtr(s.maxHeartRate)
+		}
+	}
+}
+
+func nullIntPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int64)
+	return &v
+}
+
+// GetWorkoutHeartRate vraća uzorke pulsa za jedan trening
+func GetWorkoutHeartRate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	workoutID, _ := strconv.Atoi(r.URL.Query().Get("id"))
+
+	var ownerID int
+	if err := utils.DB.QueryRow("SELECT user_id FROM workouts WHERE id = ?", workoutID).Scan(&ownerID); err == sql.ErrNoRows {
+		utils.JSONError(w, "Workout not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "error checking workout ownership", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	} else if ownerID != userID {
+		utils.JSONError(w, "Unauthorized", http.StatusForbidden)
+		return
+	}
+
+	samples, err := loadHeartRateSamples(userID, []int{workoutID})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error loading heart rate samples", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result := samples[workoutID]
+	if result == nil {
+		result = []models.HeartRateSample{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 func DeleteWorkout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -203,23 +486,24 @@ func DeleteWorkout(w http.ResponseWriter, r *http.Request) {
 
 	var ownerID int
 	if err := utils.DB.QueryRow("SELECT user_id FROM workouts WHERE id = ?", workoutID).Scan(&ownerID); err == sql.ErrNoRows {
-		http.Error(w, "Workout not found", http.StatusNotFound)
+		utils.JSONError(w, "Workout not found", http.StatusNotFound)
 		return
 	} else if err != nil {
-		log.Printf("❌ Error checking workout ownership: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error checking workout ownership", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	} else if ownerID != userID {
-		http.Error(w, "Unauthorized", http.StatusForbidden)
+		utils.JSONError(w, "Unauthorized", http.StatusForbidden)
 		return
 	}
 
 	_, err := utils.DB.Exec("DELETE FROM workouts WHERE id = ?", workoutID)
 	if err != nil {
-		log.Printf("❌ Error deleting workout: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to delete workout: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error deleting workout", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to delete workout: %v", err), http.StatusInternalServerError)
 		return
 	}
+	refreshGoal(r.Context(), userID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Workout deleted successfully"})
@@ -229,13 +513,13 @@ func DeleteWorkout(w http.ResponseWriter, r *http.Request) {
 
 func GetProgress(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	userID := middleware.GetUserID(r)
 	if userID == 0 {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
@@ -244,8 +528,8 @@ func GetProgress(w http.ResponseWriter, r *http.Request) {
 		userID,
 	)
 	if err != nil {
-		log.Printf("❌ Error querying progress: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error querying progress", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
 	defer rows.Close()
@@ -256,7 +540,7 @@ func GetProgress(w http.ResponseWriter, r *http.Request) {
 		var bodyFat, muscleMass sql.NullFloat64
 		var notes sql.NullString
 		if err := rows.Scan(&progress.ID, &progress.UserID, &progress.Weight, &bodyFat, &muscleMass, &notes, &progress.ProgressDate, &progress.CreatedAt, &progress.UpdatedAt); err != nil {
-			log.Printf("❌ Error scanning progress row: %v", err)
+			slog.ErrorContext(r.Context(), "error scanning progress row", "error", err)
 			continue
 		}
 		if bodyFat.Valid {
@@ -272,8 +556,8 @@ func GetProgress(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("❌ Error iterating progress rows: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error iterating progress rows", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -283,13 +567,13 @@ func GetProgress(w http.ResponseWriter, r *http.Request) {
 
 func CreateProgress(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	userID := middleware.GetUserID(r)
 	if userID == 0 {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
@@ -297,25 +581,25 @@ func CreateProgress(w http.ResponseWriter, r *http.Request) {
 	var userExists int
 	err := utils.DB.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", userID).Scan(&userExists)
 	if err != nil {
-		log.Printf("❌ Error checking if user exists: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error checking if user exists", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if userExists == 0 {
-		log.Printf("❌ User with ID %d does not exist in database", userID)
-		http.Error(w, "User not found. Please log in again.", http.StatusUnauthorized)
+		slog.WarnContext(r.Context(), "user from token does not exist in database")
+		utils.JSONError(w, "User not found. Please log in again.", http.StatusUnauthorized)
 		return
 	}
 
 	var req models.ProgressRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	progressDate, err := time.Parse("2006-01-02", req.ProgressDate)
 	if err != nil {
-		http.Error(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
 		return
 	}
 
@@ -324,8 +608,8 @@ func CreateProgress(w http.ResponseWriter, r *http.Request) {
 		userID, req.Weight, req.BodyFat, req.MuscleMass, req.Notes, progressDate,
 	)
 	if err != nil {
-		log.Printf("❌ Error creating progress: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to create progress entry: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error creating progress", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to create progress entry: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -337,8 +621,8 @@ func CreateProgress(w http.ResponseWriter, r *http.Request) {
 		&progress.ID, &progress.UserID, &progress.Weight, &bodyFat, &muscleMass, &notes, &progress.ProgressDate, &progress.CreatedAt, &progress.UpdatedAt,
 	)
 	if err != nil {
-		log.Printf("❌ Error fetching created progress: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to fetch created progress: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error fetching created progress", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to fetch created progress: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if bodyFat.Valid {
@@ -350,6 +634,8 @@ func CreateProgress(w http.ResponseWriter, r *http.Request) {
 	if notes.Valid {
 		progress.Notes = notes.String
 	}
+	refreshAchievements(r.Context(), userID)
+	refreshGoal(r.Context(), userID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -358,7 +644,7 @@ func CreateProgress(w http.ResponseWriter, r *http.Request) {
 
 func UpdateProgress(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -367,33 +653,33 @@ func UpdateProgress(w http.ResponseWriter, r *http.Request) {
 
 	var ownerID int
 	if err := utils.DB.QueryRow("SELECT user_id FROM progress WHERE id = ?", progressID).Scan(&ownerID); err == sql.ErrNoRows {
-		http.Error(w, "Progress entry not found", http.StatusNotFound)
+		utils.JSONError(w, "Progress entry not found", http.StatusNotFound)
 		return
 	} else if err != nil {
-		log.Printf("❌ Error checking progress ownership: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error checking progress ownership", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	} else if ownerID != userID {
-		http.Error(w, "Unauthorized", http.StatusForbidden)
+		utils.JSONError(w, "Unauthorized", http.StatusForbidden)
 		return
 	}
 
 	var req models.ProgressRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 	progressDate, err := time.Parse("2006-01-02", req.ProgressDate)
 	if err != nil {
-		http.Error(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
 		return
 	}
 
 	_, err = utils.DB.Exec("UPDATE progress SET weight = ?, body_fat = ?, muscle_mass = ?, notes = ?, progress_date = ? WHERE id = ?",
 		req.Weight, req.BodyFat, req.MuscleMass, req.Notes, progressDate, progressID)
 	if err != nil {
-		log.Printf("❌ Error updating progress: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to update progress: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error updating progress", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to update progress: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -404,8 +690,8 @@ func UpdateProgress(w http.ResponseWriter, r *http.Request) {
 		&progress.ID, &progress.UserID, &progress.Weight, &bodyFat, &muscleMass, &notes, &progress.ProgressDate, &progress.CreatedAt, &progress.UpdatedAt,
 	)
 	if err != nil {
-		log.Printf("❌ Error fetching updated progress: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to fetch updated progress: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error fetching updated progress", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to fetch updated progress: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if bodyFat.Valid {
@@ -417,6 +703,7 @@ func UpdateProgress(w http.ResponseWriter, r *http.Request) {
 	if notes.Valid {
 		progress.Notes = notes.String
 	}
+	recheckGoal(r.Context(), userID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(progress)
@@ -424,7 +711,7 @@ func UpdateProgress(w http.ResponseWriter, r *http.Request) {
 
 func DeleteProgress(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -433,23 +720,24 @@ func DeleteProgress(w http.ResponseWriter, r *http.Request) {
 
 	var ownerID int
 	if err := utils.DB.QueryRow("SELECT user_id FROM progress WHERE id = ?", progressID).Scan(&ownerID); err == sql.ErrNoRows {
-		http.Error(w, "Progress entry not found", http.StatusNotFound)
+		utils.JSONError(w, "Progress entry not found", http.StatusNotFound)
 		return
 	} else if err != nil {
-		log.Printf("❌ Error checking progress ownership: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error checking progress ownership", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	} else if ownerID != userID {
-		http.Error(w, "Unauthorized", http.StatusForbidden)
+		utils.JSONError(w, "Unauthorized", http.StatusForbidden)
 		return
 	}
 
 	_, err := utils.DB.Exec("DELETE FROM progress WHERE id = ?", progressID)
 	if err != nil {
-		log.Printf("❌ Error deleting progress: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to delete progress: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error deleting progress", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to delete progress: %v", err), http.StatusInternalServerError)
 		return
 	}
+	recheckGoal(r.Context(), userID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Progress entry deleted successfully"})
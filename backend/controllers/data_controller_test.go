@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+// storedWorkoutColumns su kolone koje UpdateWorkout čita pre izmene
+var storedWorkoutColumns = []string{"user_id", "activity_type", "duration", "intensity", "calories_burned", "calories_method",
+	"distance_km", "elevation_gain_m", "avg_heart_rate", "max_heart_rate"}
+
+func updateWorkoutRequest(userID int, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPut, "/api/workouts/update?id=7", strings.NewReader(body))
+	return r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, userID))
+}
+
+// fetchedWorkoutRow je red koji fetchWorkout čita posle izmene (kolone iz workoutColumns)
+func fetchedWorkoutRow(calories float64, method string) []driver.Value {
+	now := time.Now()
+	return []driver.Value{int64(7), int64(1), "Renamed", nil, nil, int64(45), nil, calories, method,
+		nil, nil, nil, nil, "manual", nil, now, now, now}
+}
+
+func TestUpdateWorkoutKeepsStoredCalories(t *testing.T) {
+	tests := []struct {
+		name       string
+		stored     []driver.Value
+		body       string
+		wantKcal   float64
+		wantMethod string
+	}{
+		{
+			name:       "rename device workout",
+			stored:     []driver.Value{int64(1), "running", int64(42), nil, 512.3, models.CaloriesMethodDevice, 8.1, 40.0, int64(150), int64(172)},
+			body:       `{"name":"Morning run","duration":42,"workout_date":"2024-05-01"}`,
+			wantKcal:   512.3,
+			wantMethod: models.CaloriesMethodDevice,
+		},
+		{
+			name:       "rename manual workout without activity type",
+			stored:     []driver.Value{int64(1), nil, int64(45), int64(3), 300.0, models.CaloriesMethodManual, nil, nil, nil, nil},
+			body:       `{"name":"Renamed","duration":45,"workout_date":"2024-05-01"}`,
+			wantKcal:   300,
+			wantMethod: models.CaloriesMethodManual,
+		},
+		{
+			name:       "duration change re-estimates MET calories",
+			stored:     []driver.Value{int64(1), "running", int64(30), nil, 300.0, models.CaloriesMethodMET, nil, nil, nil, nil},
+			body:       `{"name":"Run","duration":60,"workout_date":"2024-05-01"}`,
+			wantKcal:   utils.CaloriesFromMET(10, 80, 60),
+			wantMethod: models.CaloriesMethodMET,
+		},
+		{
+			name:       "explicit calories override the stored value",
+			stored:     []driver.Value{int64(1), "running", int64(42), nil, 512.3, models.CaloriesMethodDevice, nil, nil, nil, nil},
+			body:       `{"name":"Run","duration":42,"calories_burned":450,"workout_date":"2024-05-01"}`,
+			wantKcal:   450,
+			wantMethod: models.CaloriesMethodManual,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			db.on("SELECT user_id, activity_type, duration", storedWorkoutColumns, tt.stored)
+			db.on("SELECT met FROM activity_types", []string{"met"}, []driver.Value{10.0})
+			db.on("SELECT weight FROM progress", []string{"weight"}, []driver.Value{80.0})
+			db.on("FROM workouts WHERE id = ?", strings.Split(workoutColumns, ", "), fetchedWorkoutRow(tt.wantKcal, tt.wantMethod))
+
+			w := httptest.NewRecorder()
+			UpdateWorkout(w, updateWorkoutRequest(1, tt.body))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+			}
+
+			update, ok := db.executed("UPDATE workouts SET")
+			if !ok {
+				t.Fatal("workout was not updated")
+			}
+			// Argumenti: name, description, activity_type, duration, intensity, calories_burned, calories_method, ...
+			if kcal, method := update.args[5], update.args[6]; kcal != tt.wantKcal || method != tt.wantMethod {
+				t.Fatalf("saved calories = %v (%v), want %v (%v)", kcal, method, tt.wantKcal, tt.wantMethod)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend/models"
+	"backend/utils"
+)
+
+// maxExercisesPerWorkout ograničava broj vežbi u jednom treningu
+const maxExercisesPerWorkout = 50
+
+// muscleGroups su dozvoljene oznake mišićnih grupa za vežbe
+var muscleGroups = map[string]bool{
+	"chest": true, "back": true, "shoulders": true, "biceps": true, "triceps": true, "forearms": true,
+	"core": true, "quads": true, "hamstrings": true, "glutes": true, "calves": true, "full_body": true,
+}
+
+var (
+	errInvalidIntensity = errors.New("intensity must be between 1 and 10 (RPE)")
+	errTooManyExercises = fmt.Errorf("a workout can have at most %d exercises", maxExercisesPerWorkout)
+)
+
+// validateStrengthRequest proverava intenzitet i vežbe iz zahteva za trening
+func validateStrengthRequest(req models.WorkoutRequest) error {
+	if req.Intensity != nil && (*req.Intensity < 1 || *req.Intensity > 10) {
+		return errInvalidIntensity
+	}
+	if len(req.Exercises) > maxExercisesPerWorkout {
+		return errTooManyExercises
+	}
+	for i, exercise := range req.Exercises {
+		if strings.TrimSpace(exercise.Name) == "" {
+			return fmt.Errorf("exercises[%d]: name is required", i)
+		}
+		if !muscleGroups[exercise.MuscleGroup] {
+			return fmt.Errorf("exercises[%d]: unknown muscle_group %q", i, exercise.MuscleGroup)
+		}
+		if exercise.Sets < 1 || exercise.Sets > 100 || exercise.Reps < 1 || exercise.Reps > 1000 {
+			return fmt.Errorf("exercises[%d]: sets must be 1-100 and reps 1-1000", i)
+		}
+		if exercise.WeightKg != nil && (*exercise.WeightKg < 0 || *exercise.WeightKg > 1000) {
+			return fmt.Errorf("exercises[%d]: weight_kg must be between 0 and 1000", i)
+		}
+	}
+	return nil
+}
+
+// replaceWorkoutExercises briše postojeće vežbe treninga i upisuje nove u okviru transakcije
+func replaceWorkoutExercises(tx *sql.Tx, workoutID int64, exercises []models.WorkoutExercise) error {
+	if _, err := tx.Exec("DELETE FROM workout_exercises WHERE workout_id = ?", workoutID); err != nil {
+		return fmt.Errorf("failed to delete workout exercises: %w", err)
+	}
+	if len(exercises) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?, ?),", len(exercises)), ",")
+	args := make([]interface{}, 0, len(exercises)*7)
+	for i, exercise := range exercises {
+		args = append(args, workoutID, i+1, strings.TrimSpace(exercise.Name), exercise.MuscleGroup, exercise.Sets, exercise.Reps, exercise.WeightKg)
+	}
+	if _, err := tx.Exec("INSERT INTO workout_exercises (workout_id, position, name, muscle_group, sets, reps, weight_kg) VALUES "+placeholders, args...); err != nil {
+		return fmt.Errorf("failed to insert workout exercises: %w", err)
+	}
+	return nil
+}
+
+// attachExercises učitava vežbe za sve treninge iz liste jednim upitom
+func attachExercises(userID int, workouts []models.Workout) error {
+	if len(workouts) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(workouts)), ",")
+	args := []interface{}{userID}
+	index := make(map[int]int, len(workouts))
+	for i, workout := range workouts {
+		args = append(args, workout.ID)
+		index[workout.ID] = i
+	}
+
+	rows, err := utils.DB.Query(
+		"SELECT e.workout_id, e.name, e.muscle_group, e.sets, e.reps, e.weight_kg FROM workout_exercises e "+
+			"JOIN workouts w ON w.id = e.workout_id WHERE w.user_id = ? AND e.workout_id IN ("+placeholders+") "+
+			"ORDER BY e.workout_id, e.position",
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query workout exercises: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var workoutID int
+		var exercise models.WorkoutExercise
+		var weight sql.NullFloat64
+		if err := rows.Scan(&workoutID, &exercise.Name, &exercise.MuscleGroup, &exercise.Sets, &exercise.Reps, &weight); err != nil {
+			return fmt.Errorf("failed to scan workout exercise: %w", err)
+		}
+		exercise.WeightKg = nullFloatPtr(weight)
+		if i, ok := index[workoutID]; ok {
+			workouts[i].Exercises = append(workouts[i].Exercises, exercise)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/middleware"
+	"backend/utils"
+)
+
+// csvFlushEvery određuje posle koliko redova se CSV šalje klijentu
+const csvFlushEvery = 500
+
+// csvDataset opisuje jednu tabelu koja se izvozi u CSV. Upit mora da prima
+// user_id, from i to parametre (datumi su opcioni, NULL znači bez granice).
+type csvDataset struct {
+	name   string
+	header []string
+	query  string
+	row    func(rows *sql.Rows) ([]string, error)
+}
+
+var workoutsCSV = csvDataset{
+	name:   "workouts",
+	header: []string{"id", "workout_date", "name", "description", "activity_type", "duration_min", "intensity", "calories_burned", "calories_method", "distance_km", "elevation_gain_m", "avg_heart_rate", "max_heart_rate", "source", "started_at", "created_at"},
+	query: "SELECT id, workout_date, name, description, activity_type, duration, intensity, calories_burned, calories_method, distance_km, elevation_gain_m, avg_heart_rate, max_heart_rate, source, started_at, created_at " +
+		"FROM workouts WHERE user_id = ? AND (? IS NULL OR workout_date >= ?) AND (? IS NULL OR workout_date <= ?) ORDER BY workout_date, id",
+	row: func(rows *sql.Rows) ([]string, error) {
+		var id, duration int
+		var date, createdAt time.Time
+		var name string
+		var calories float64
+		var method string
+		var description, activityType, source sql.NullString
+		var distance, elevation sql.NullFloat64
+		var intensity, avgHR, maxHR sql.NullInt64
+		var startedAt sql.NullTime
+		if err := rows.Scan(&id, &date, &name, &description, &activityType, &duration, &intensity, &calories, &method,
+			&distance, &elevation, &avgHR, &maxHR, &source, &startedAt, &createdAt); err != nil {
+			return nil, err
+		}
+		return []string{
+			strconv.Itoa(id), date.Format("2006-01-02"), csvText(name), csvText(description.String), activityType.String,
+			strconv.Itoa(duration), csvNullInt(intensity), csvFloat(calories), method, csvNullFloat(distance), csvNullFloat(elevation),
+			csvNullInt(avgHR), csvNullInt(maxHR), source.String, csvNullTime(startedAt), createdAt.Format(time.RFC3339),
+		}, nil
+	},
+}
+
+var progressCSV = csvDataset{
+	name:   "progress",
+	header: []string{"id", "progress_date", "weight_kg", "body_fat_pct", "muscle_mass_kg", "notes", "created_at"},
+	query: "SELECT id, progress_date, weight, body_fat, muscle_mass, notes, created_at " +
+		"FROM progress WHERE user_id = ? AND (? IS NULL OR progress_date >= ?) AND (? IS NULL OR progress_date <= ?) ORDER BY progress_date, id",
+	row: func(rows *sql.Rows) ([]string, error) {
+		var id int
+		var date, createdAt time.Time
+		var weight float64
+		var bodyFat, muscleMass sql.NullFloat64
+		var notes sql.NullString
+		if err := rows.Scan(&id, &date, &weight, &bodyFat, &muscleMass, &notes, &createdAt); err != nil {
+			return nil, err
+		}
+		return []string{
+			strconv.Itoa(id), date.Format("2006-01-02"), csvFloat(weight), csvNullFloat(bodyFat), csvNullFloat(muscleMass),
+			csvText(notes.String), createdAt.Format(time.RFC3339),
+		}, nil
+	},
+}
+
+var waterCSV = csvDataset{
+	name:   "water",
+	header: []string{"id", "logged_at", "amount_ml"},
+	query: "SELECT id, logged_at, amount_ml " +
+		"FROM water_logs WHERE user_id = ? AND (? IS NULL OR DATE(logged_at) >= ?) AND (? IS NULL OR DATE(logged_at) <= ?) ORDER BY logged_at, id",
+	row: func(rows *sql.Rows) ([]string, error) {
+		var id, amount int
+		var loggedAt time.Time
+		if err := rows.Scan(&id, &loggedAt, &amount); err != nil {
+			return nil, err
+		}
+		return []string{strconv.Itoa(id), loggedAt.Format(time.RFC3339), strconv.Itoa(amount)}, nil
+	},
+}
+
+// csvBundle su sve tabele koje ulaze u ZIP izvoz
+var csvBundle = []csvDataset{workoutsCSV, progressCSV, waterCSV}
+
+// ExportWorkoutsCSV izvozi treninge korisnika kao CSV (opciono ?from=YYYY-MM-DD&to=YYYY-MM-DD)
+func ExportWorkoutsCSV(w http.ResponseWriter, r *http.Request) {
+	exportCSV(w, r, workoutsCSV)
+}
+
+// ExportProgressCSV izvozi unose napretka korisnika kao CSV
+func ExportProgressCSV(w http.ResponseWriter, r *http.Request) {
+	exportCSV(w, r, progressCSV)
+}
+
+// ExportBundle izvozi sve tabele korisnika kao ZIP sa jednim CSV fajlom po tabeli
+func ExportBundle(w http.ResponseWriter, r *http.Request) {
+	userID, from, to, ok := parseExportRequest(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"fitness-export-%s.zip\"", time.Now().Format("2006-01-02")))
+
+	archive := zip.NewWriter(w)
+	for _, dataset := range csvBundle {
+		entry, err := archive.Create(dataset.name + ".csv")
+		if err != nil {
+			slog.ErrorContext(r.Context(), "error creating zip entry", "dataset", dataset.name, "error", err)
+			return
+		}
+		if err := writeCSV(entry, nil, userID, dataset, from, to); err != nil {
+			// Zaglavlje je već poslato, pa se greška samo loguje i arhiva ostaje nekompletna
+			slog.ErrorContext(r.Context(), "error exporting dataset", "dataset", dataset.name, "error", err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		slog.ErrorContext(r.Context(), "error finalizing zip export", "error", err)
+	}
+}
+
+func exportCSV(w http.ResponseWriter, r *http.Request, dataset csvDataset) {
+	userID, from, to, ok := parseExportRequest(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-%s.csv\"", dataset.name, time.Now().Format("2006-01-02")))
+
+	if err := writeCSV(w, http.NewResponseController(w).Flush, userID, dataset, from, to); err != nil {
+		slog.ErrorContext(r.Context(), "error exporting dataset", "dataset", dataset.name, "error", err)
+	}
+}
+
+// parseExportRequest proverava metod, korisnika i opcioni opseg datuma
+func parseExportRequest(w http.ResponseWriter, r *http.Request) (int, *time.Time, *time.Time, bool) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return 0, nil, nil, false
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, nil, nil, false
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return 0, nil, nil, false
+	}
+	return userID, from, to, true
+}
+
+// parseDateRange čita opcione ?from= i ?to= parametre u formatu YYYY-MM-DD
+func parseDateRange(r *http.Request) (*time.Time, *time.Time, error) {
+	parse := func(name string) (*time.Time, error) {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			return nil, nil
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s date format. Use YYYY-MM-DD", name)
+		}
+		return &date, nil
+	}
+
+	from, err := parse("from")
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := parse("to")
+	if err != nil {
+		return nil, nil, err
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		return nil, nil, errors.New("'to' date must not be before 'from' date")
+	}
+	return from, to, nil
+}
+
+// writeCSV streamuje redove iz baze direktno u writer, bez učitavanja cele tabele u memoriju.
+// flush (može biti nil) šalje klijentu ono što je do tada upisano.
+func writeCSV(out io.Writer, flush func() error, userID int, dataset csvDataset, from, to *time.Time) error {
+	rows, err := utils.DB.Query(dataset.query, userID, from, from, to, to)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", dataset.name, err)
+	}
+	defer rows.Close()
+
+	writer := csv.NewWriter(out)
+	if err := writer.Write(dataset.header); err != nil {
+		return err
+	}
+
+	count := 0
+	for rows.Next() {
+		record, err := dataset.row(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan %s row: %w", dataset.name, err)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		count++
+		if count%csvFlushEvery == 0 {
+			writer.Flush()
+			if flush != nil {
+				if err := flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+					return err
+				}
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate %s rows: %w", dataset.name, err)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText štiti od CSV/formula injekcije: tekst koji počinje znakom koji
+// Excel tumači kao formulu dobija prefiks apostrofa
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func csvFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func csvNullFloat(value sql.NullFloat64) string {
+	if !value.Valid {
+		return ""
+	}
+	return csvFloat(value.Float64)
+}
+
+func csvNullInt(value sql.NullInt64) string {
+	if !value.Valid {
+		return ""
+	}
+	return strconv.FormatInt(value.Int64, 10)
+}
+
+func csvNullTime(value sql.NullTime) string {
+	if !value.Valid {
+		return ""
+	}
+	return value.Time.Format(time.RFC3339)
+}
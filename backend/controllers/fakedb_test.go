@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"backend/utils"
+)
+
+// fakeDB je skriptovana baza za testove handler-a. Upit dobija redove prvog pravila
+// čiji je tekst deo upita; upiti bez pravila vraćaju prazan rezultat. Exec naredbe
+// se beleže da bi test proverio šta je upisano.
+type fakeDB struct {
+	mu    sync.Mutex
+	rules []fakeRule
+	execs []fakeStatement
+	// affected, ako je postavljen, određuje RowsAffected za Exec (podrazumevano 1)
+	affected func(statement fakeStatement) int64
+}
+
+type fakeRule struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+type fakeStatement struct {
+	query string
+	args  []driver.Value
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+	fakeOnce  sync.Once
+)
+
+// newFakeDB postavlja utils.DB na novu skriptovanu bazu do kraja testa
+func newFakeDB(t *testing.T) *fakeDB {
+	t.Helper()
+	fakeOnce.Do(func() { sql.Register("fakedb", fakeDriver{}) })
+
+	db := &fakeDB{}
+	fakeDBsMu.Lock()
+	name := fmt.Sprintf("%s-%d", t.Name(), len(fakeDBs))
+	fakeDBs[name] = db
+	fakeDBsMu.Unlock()
+
+	conn, err := sql.Open("fakedb", name)
+	if err != nil {
+		t.Fatalf("open fake db: %v", err)
+	}
+	saved := utils.DB
+	utils.DB = conn
+	t.Cleanup(func() {
+		conn.Close()
+		utils.DB = saved
+	})
+	return db
+}
+
+// on dodaje pravilo: upit koji sadrži match vraća date kolone i redove
+func (db *fakeDB) on(match string, columns []string, rows ...[]driver.Value) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.rules = append(db.rules, fakeRule{match: match, columns: columns, rows: rows})
+}
+
+// executed vraća prvu izvršenu naredbu koja sadrži match
+func (db *fakeDB) executed(match string) (fakeStatement, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, statement := range db.execs {
+		if strings.Contains(statement.query, match) {
+			return statement, true
+		}
+	}
+	return fakeStatement{}, false
+}
+
+func (db *fakeDB) query(query string) *fakeRows {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, rule := range db.rules {
+		if strings.Contains(query, rule.match) {
+			return &fakeRows{columns: rule.columns, rows: rule.rows}
+		}
+	}
+	return &fakeRows{}
+}
+
+func (db *fakeDB) exec(query string, args []driver.Value) int64 {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	statement := fakeStatement{query: query, args: args}
+	db.execs = append(db.execs, statement)
+	if db.affected != nil {
+		return db.affected(statement)
+	}
+	return 1
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	db, ok := fakeDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake db %q", name)
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(s.db.exec(s.query, args)), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return s.db.query(s.query), nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
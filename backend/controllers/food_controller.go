@@ -4,14 +4,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"backend/metrics"
 	"backend/middleware"
 	"backend/models"
 	"backend/utils"
 )
 
+// openFoodFactsURL je osnovna adresa Open Food Facts API-ja
+const openFoodFactsURL = "https://world.openfoodfacts.org"
+
+// foodAPIClient poziva Open Food Facts API i beleži metrike poziva
+var foodAPIClient = &http.Client{
+	Timeout:   10 * time.Second,
+	Transport: metrics.FoodAPITransport(http.DefaultTransport),
+}
+
 // Open Food Facts API odgovor
 type OFFProduct struct {
 	Product struct {
@@ -29,39 +40,39 @@ type OFFProduct struct {
 // SearchFood pretražuje hranu koristeći Open Food Facts API
 func SearchFood(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req models.FoodSearchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// Pozivanje Open Food Facts API
-	url := fmt.Sprintf("https://world.openfoodfacts.org/api/v2/product/%s.json", req.Barcode)
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("%s/api/v2/product/%s.json", openFoodFactsURL, req.Barcode)
+	resp, err := foodAPIClient.Get(url)
 	if err != nil {
-		http.Error(w, "Failed to fetch food data", http.StatusInternalServerError)
+		utils.JSONError(w, "Failed to fetch food data", http.StatusInternalServerError)
 		return
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		http.Error(w, "Failed to read response", http.StatusInternalServerError)
+		utils.JSONError(w, "Failed to read response", http.StatusInternalServerError)
 		return
 	}
 
 	var product OFFProduct
 	if err := json.Unmarshal(body, &product); err != nil {
-		http.Error(w, "Failed to parse response", http.StatusInternalServerError)
+		utils.JSONError(w, "Failed to parse response", http.StatusInternalServerError)
 		return
 	}
 
 	if product.Status == 0 {
-		http.Error(w, "Product not found", http.StatusNotFound)
+		utils.JSONError(w, "Product not found", http.StatusNotFound)
 		return
 	}
 
@@ -81,14 +92,14 @@ func SearchFood(w http.ResponseWriter, r *http.Request) {
 // GenerateMealPlan generiše plan ishrane na osnovu korisnikov cilja
 func GenerateMealPlan(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Preuzimaje user ID iz konteksta (setovano pomoću auth middleware-a)
 	userID := middleware.GetUserID(r)
 	if userID == 0 {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
@@ -96,13 +107,13 @@ func GenerateMealPlan(w http.ResponseWriter, r *http.Request) {
 	var userExists int
 	err := utils.DB.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", userID).Scan(&userExists)
 	if err != nil {
-		log.Printf("❌ Error checking if user exists: %v", err)
-		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "error checking if user exists", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
 	if userExists == 0 {
-		log.Printf("❌ User with ID %d does not exist in database", userID)
-		http.Error(w, "User not found. Please log in again.", http.StatusUnauthorized)
+		slog.WarnContext(r.Context(), "user from token does not exist in database")
+		utils.JSONError(w, "User not found. Please log in again.", http.StatusUnauthorized)
 		return
 	}
 
@@ -110,7 +121,7 @@ func GenerateMealPlan(w http.ResponseWriter, r *http.Request) {
 	var goal string
 	err = utils.DB.QueryRow("SELECT goal FROM users WHERE id = ?", userID).Scan(&goal)
 	if err != nil {
-		http.Error(w, "User not found", http.StatusNotFound)
+		utils.JSONError(w, "User not found", http.StatusNotFound)
 		return
 	}
 
@@ -119,8 +130,8 @@ func GenerateMealPlan(w http.ResponseWriter, r *http.Request) {
 	var foods []models.Food
 
 	for _, barcode := range barcodes {
-		url := fmt.Sprintf("https://world.openfoodfacts.org/api/v2/product/%s.json", barcode)
-		resp, err := http.Get(url)
+		url := fmt.Sprintf("%s/api/v2/product/%s.json", openFoodFactsURL, barcode)
+		resp, err := foodAPIClient.Get(url)
 		if err != nil {
 			continue
 		}
@@ -0,0 +1,470 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+// onTrackTolerancePercent je dozvoljeno zaostajanje za očekivanim napretkom
+const onTrackTolerancePercent = 5.0
+
+const goalColumns = "id, goal_type, target_weight, target_body_fat, target_date, weekly_workout_target, start_weight, start_body_fat, start_date, status, ended_at, created_at"
+
+// GetGoal vraća trenutni cilj korisnika (aktivan ili upravo ostvaren) sa procentom
+// ostvarenja i procenom da li je korisnik na dobrom putu
+func GetGoal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	goal, err := scanGoal(utils.DB.QueryRow(
+		"SELECT "+goalColumns+" FROM user_goals WHERE user_id = ? AND status <> ? ORDER BY id DESC LIMIT 1",
+		userID, models.GoalStatusReplaced,
+	))
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "No goal set", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	progress, err := evaluateGoal(r.Context(), userID, goal)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error evaluating goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// CreateGoal postavlja novi cilj. Prethodni aktivni cilj ostaje u istoriji sa statusom 'replaced'.
+func CreateGoal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.GoalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var currentGoal string
+	err := utils.DB.QueryRow("SELECT goal FROM users WHERE id = ?", userID).Scan(&currentGoal)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching user goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if req.GoalType == "" {
+		req.GoalType = currentGoal
+	}
+
+	startWeight, startBodyFat, err := currentBodyMeasurements(userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching start measurements", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	today := truncateToDay(time.Now())
+	targetDate, err := validateGoalRequest(req, today, startWeight, startBodyFat)
+	if err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error starting transaction", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"UPDATE user_goals SET status = ?, ended_at = NOW() WHERE user_id = ? AND status = ?",
+		models.GoalStatusReplaced, userID, models.GoalStatusActive,
+	); err != nil {
+		slog.ErrorContext(r.Context(), "error replacing previous goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to save goal: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := tx.Exec(
+		"INSERT INTO user_goals (user_id, goal_type, target_weight, target_body_fat, target_date, weekly_workout_target, start_weight, start_body_fat, start_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		userID, req.GoalType, req.TargetWeight, req.TargetBodyFat, targetDate, req.WeeklyWorkoutTarget, startWeight, startBodyFat, today.Format("2006-01-02"),
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error inserting goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to save goal: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Tip cilja i nedeljni cilj se drže i na profilu, koji koriste plan ishrane i nizovi treninga
+	if _, err := tx.Exec(
+		"UPDATE users SET goal = ?, weekly_workout_target = COALESCE(?, weekly_workout_target) WHERE id = ?",
+		req.GoalType, req.WeeklyWorkoutTarget, userID,
+	); err != nil {
+		slog.ErrorContext(r.Context(), "error updating profile goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to save goal: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.ErrorContext(r.Context(), "error committing goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to save goal: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	goalID, _ := result.LastInsertId()
+	goal, err := scanGoal(utils.DB.QueryRow("SELECT "+goalColumns+" FROM user_goals WHERE id = ?", goalID))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching created goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	progress, err := evaluateGoal(r.Context(), userID, goal)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error evaluating goal", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "goal set", "goal_type", req.GoalType, "goal_id", goalID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(progress)
+}
+
+// GetGoalHistory vraća sve ciljeve korisnika, od najnovijeg
+func GetGoalHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := utils.DB.Query("SELECT "+goalColumns+" FROM user_goals WHERE user_id = ? ORDER BY id DESC", userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error querying goals", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	goals := []models.Goal{}
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "error scanning goal row", "error", err)
+			continue
+		}
+		goals = append(goals, goal)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(r.Context(), "error iterating goal rows", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goals)
+}
+
+// validateGoalRequest proverava vrednosti cilja i vraća rok kao datum (ili nil).
+// Ciljna vrednost mora da se razlikuje od početne i da bude u smeru tipa cilja:
+// mršavljenje traži manju težinu i manji procenat masti, hipertrofija veću težinu.
+func validateGoalRequest(req models.GoalRequest, today time.Time, startWeight, startBodyFat *float64) (*string, error) {
+	if req.GoalType != "lose_weight" && req.GoalType != "hypertrophy" {
+		return nil, errors.New("goal_type must be 'lose_weight' or 'hypertrophy'")
+	}
+	if req.TargetWeight == nil && req.TargetBodyFat == nil && req.WeeklyWorkoutTarget == nil {
+		return nil, errors.New("Set at least one of target_weight, target_body_fat or weekly_workout_target")
+	}
+	if req.TargetWeight != nil && *req.TargetWeight <= 0 {
+		return nil, errors.New("target_weight must be positive")
+	}
+	if req.TargetBodyFat != nil && (*req.TargetBodyFat <= 0 || *req.TargetBodyFat >= 100) {
+		return nil, errors.New("target_body_fat must be between 0 and 100")
+	}
+	if req.WeeklyWorkoutTarget != nil && (*req.WeeklyWorkoutTarget < 1 || *req.WeeklyWorkoutTarget > maxWeeklyWorkoutTarget) {
+		return nil, fmt.Errorf("weekly_workout_target must be between 1 and %d", maxWeeklyWorkoutTarget)
+	}
+	if req.TargetWeight != nil && startWeight != nil {
+		switch {
+		case *req.TargetWeight == *startWeight:
+			return nil, errors.New("target_weight must differ from the current weight")
+		case req.GoalType == "lose_weight" && *req.TargetWeight > *startWeight:
+			return nil, errors.New("target_weight must be below the current weight for a lose_weight goal")
+		case req.GoalType == "hypertrophy" && *req.TargetWeight < *startWeight:
+			return nil, errors.New("target_weight must be above the current weight for a hypertrophy goal")
+		}
+	}
+	if req.TargetBodyFat != nil && startBodyFat != nil {
+		switch {
+		case *req.TargetBodyFat == *startBodyFat:
+			return nil, errors.New("target_body_fat must differ from the current body fat")
+		case req.GoalType == "lose_weight" && *req.TargetBodyFat > *startBodyFat:
+			return nil, errors.New("target_body_fat must be below the current body fat for a lose_weight goal")
+		}
+	}
+	if req.TargetDate == nil {
+		return nil, nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", *req.TargetDate, time.Local)
+	if err != nil {
+		return nil, errors.New("Invalid target_date format. Use YYYY-MM-DD")
+	}
+	if !date.After(today) {
+		return nil, errors.New("target_date must be in the future")
+	}
+	formatted := date.Format("2006-01-02")
+	return &formatted, nil
+}
+
+// scanGoal čita jedan red iz user_goals (kolone iz goalColumns)
+func scanGoal(row rowScanner) (models.Goal, error) {
+	var goal models.Goal
+	var targetWeight, targetBodyFat, startWeight, startBodyFat sql.NullFloat64
+	var targetDate, endedAt sql.NullTime
+	var weeklyTarget sql.NullInt64
+	var startDate time.Time
+	err := row.Scan(&goal.ID, &goal.GoalType, &targetWeight, &targetBodyFat, &targetDate, &weeklyTarget,
+		&startWeight, &startBodyFat, &startDate, &goal.Status, &endedAt, &goal.CreatedAt)
+	if err != nil {
+		return goal, err
+	}
+
+	goal.TargetWeight = nullFloatPtr(targetWeight)
+	goal.TargetBodyFat = nullFloatPtr(targetBodyFat)
+	goal.StartWeight = nullFloatPtr(startWeight)
+	goal.StartBodyFat = nullFloatPtr(startBodyFat)
+	if targetDate.Valid {
+		date := targetDate.Time.Format("2006-01-02")
+		goal.TargetDate = &date
+	}
+	if weeklyTarget.Valid {
+		value := int(weeklyTarget.Int64)
+		goal.WeeklyWorkoutTarget = &value
+	}
+	goal.StartDate = startDate.Format("2006-01-02")
+	if endedAt.Valid {
+		goal.EndedAt = &endedAt.Time
+	}
+	return goal, nil
+}
+
+// currentBodyMeasurements vraća poslednju težinu (ili težinu sa profila) i poslednji uneti procenat masti
+func currentBodyMeasurements(userID int) (*float64, *float64, error) {
+	var weight, bodyFat, profileWeight sql.NullFloat64
+	err := utils.DB.QueryRow(
+		"SELECT (SELECT weight FROM progress WHERE user_id = u.id ORDER BY progress_date DESC, id DESC LIMIT 1), "+
+			"(SELECT body_fat FROM progress WHERE user_id = u.id AND body_fat > 0 ORDER BY progress_date DESC, id DESC LIMIT 1), "+
+			"u.weight FROM users u WHERE u.id = ?",
+		userID,
+	).Scan(&weight, &bodyFat, &profileWeight)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !weight.Valid {
+		weight = profileWeight
+	}
+	return nullFloatPtr(weight), nullFloatPtr(bodyFat), nil
+}
+
+// evaluateGoal računa napredak aktivnog cilja. Cilj čiji su svi merljivi delovi
+// ostvareni dobija status 'achieved'.
+func evaluateGoal(ctx context.Context, userID int, goal models.Goal) (models.GoalProgress, error) {
+	progress := models.GoalProgress{Goal: goal}
+
+	weight, bodyFat, err := currentBodyMeasurements(userID)
+	if err != nil {
+		return progress, err
+	}
+	progress.CurrentWeight, progress.CurrentBodyFat = weight, bodyFat
+	progress.WeightPercent = targetPercent(goal.StartWeight, weight, goal.TargetWeight)
+	progress.BodyFatPercent = targetPercent(goal.StartBodyFat, bodyFat, goal.TargetBodyFat)
+
+	var parts []float64
+	for _, part := range []*float64{progress.WeightPercent, progress.BodyFatPercent} {
+		if part != nil {
+			parts = append(parts, *part)
+		}
+	}
+	if len(parts) > 0 {
+		sum := 0.0
+		for _, part := range parts {
+			sum += part
+		}
+		percent := math.Round(sum/float64(len(parts))*10) / 10
+		progress.PercentComplete = &percent
+	}
+
+	today := truncateToDay(time.Now())
+	thisWeek, _ := weekBounds(today)
+	lastWeek := thisWeek.AddDate(0, 0, -7)
+	err = utils.DB.QueryRow(
+		"SELECT COALESCE(SUM(workout_date >= ?), 0), COALESCE(SUM(workout_date < ?), 0) FROM workouts WHERE user_id = ? AND workout_date >= ? AND workout_date <= ?",
+		thisWeek.Format("2006-01-02"), thisWeek.Format("2006-01-02"), userID, lastWeek.Format("2006-01-02"), today.Format("2006-01-02"),
+	).Scan(&progress.WorkoutsThisWeek, &progress.WorkoutsLastWeek)
+	if err != nil {
+		return progress, err
+	}
+
+	var checks []bool
+	if goal.TargetDate != nil {
+		start, _ := time.ParseInLocation("2006-01-02", goal.StartDate, time.Local)
+		deadline, _ := time.ParseInLocation("2006-01-02", *goal.TargetDate, time.Local)
+		totalDays := deadline.Sub(start).Hours() / 24
+		elapsedDays := today.Sub(start).Hours() / 24
+		remaining := int(math.Max(0, math.Round(deadline.Sub(today).Hours()/24)))
+		progress.DaysRemaining = &remaining
+		if totalDays > 0 {
+			expected := math.Round(math.Min(100, math.Max(0, elapsedDays/totalDays*100))*10) / 10
+			progress.ExpectedPercent = &expected
+			if progress.PercentComplete != nil {
+				checks = append(checks, *progress.PercentComplete >= expected-onTrackTolerancePercent)
+			}
+		}
+	}
+	// Nedeljni cilj se proverava na prethodnoj, završenoj nedelji ako je cilj tada već važio
+	if goal.WeeklyWorkoutTarget != nil && goal.StartDate <= lastWeek.Format("2006-01-02") {
+		checks = append(checks, progress.WorkoutsLastWeek >= *goal.WeeklyWorkoutTarget)
+	}
+	if len(checks) > 0 {
+		onTrack := true
+		for _, check := range checks {
+			onTrack = onTrack && check
+		}
+		progress.OnTrack = &onTrack
+	}
+
+	if goal.Status == models.GoalStatusActive && progress.PercentComplete != nil && *progress.PercentComplete >= 100 {
+		if _, err := utils.DB.Exec(
+			"UPDATE user_goals SET status = ?, ended_at = NOW() WHERE id = ? AND status = ?",
+			models.GoalStatusAchieved, goal.ID, models.GoalStatusActive,
+		); err != nil {
+			return progress, err
+		}
+		now := time.Now()
+		progress.Goal.Status = models.GoalStatusAchieved
+		progress.Goal.EndedAt = &now
+		slog.InfoContext(ctx, "goal achieved", "goal_id", goal.ID)
+		refreshAchievements(ctx, userID)
+	}
+
+	return progress, nil
+}
+
+// refreshGoal ponovo procenjuje aktivni cilj posle novog merenja ili treninga, da bi
+// cilj bio označen kao ostvaren i bez otvaranja stranice sa ciljem. Greška se samo loguje.
+func refreshGoal(ctx context.Context, userID int) {
+	goal, err := scanGoal(utils.DB.QueryRow(
+		"SELECT "+goalColumns+" FROM user_goals WHERE user_id = ? AND status = ? ORDER BY id DESC LIMIT 1",
+		userID, models.GoalStatusActive,
+	))
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err == nil {
+		_, err = evaluateGoal(ctx, userID, goal)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error evaluating goal", "error", err)
+	}
+}
+
+// recheckGoal se poziva posle izmene ili brisanja merenja. Za razliku od refreshGoal
+// razmatra i poslednji ostvaren cilj: ako ga trenutna merenja više ne ispunjavaju,
+// status je počivao na ispravljenom ili obrisanom unosu i cilj se vraća u aktivne.
+// Novo merenje ostvaren cilj ne vraća, pa kasniji povratak težine ne briše uspeh.
+func recheckGoal(ctx context.Context, userID int) {
+	goal, err := scanGoal(utils.DB.QueryRow(
+		"SELECT "+goalColumns+" FROM user_goals WHERE user_id = ? AND status <> ? ORDER BY id DESC LIMIT 1",
+		userID, models.GoalStatusReplaced,
+	))
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err == nil {
+		var progress models.GoalProgress
+		progress, err = evaluateGoal(ctx, userID, goal)
+		if err == nil && goal.Status == models.GoalStatusAchieved && (progress.PercentComplete == nil || *progress.PercentComplete < 100) {
+			_, err = utils.DB.Exec(
+				"UPDATE user_goals SET status = ?, ended_at = NULL WHERE id = ? AND status = ?",
+				models.GoalStatusActive, goal.ID, models.GoalStatusAchieved,
+			)
+			if err == nil {
+				slog.InfoContext(ctx, "goal reopened", "goal_id", goal.ID)
+			}
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error evaluating goal", "error", err)
+	}
+}
+
+// targetPercent vraća koliki je deo puta od početne do ciljne vrednosti pređen (0-100).
+// Radi i za smanjenje i za povećanje vrednosti.
+func targetPercent(start, current, target *float64) *float64 {
+	if start == nil || current == nil || target == nil {
+		return nil
+	}
+	total := *target - *start
+	percent := 100.0
+	if total != 0 {
+		percent = math.Min(100, math.Max(0, (*current-*start)/total*100))
+	}
+	percent = math.Round(percent*10) / 10
+	return &percent
+}
+
+func nullFloatPtr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"backend/models"
+)
+
+// goalRow je red user_goals (kolone iz goalColumns) za cilj mršavljenja sa 85 na 75 kg
+func goalRow(status string) []driver.Value {
+	now := time.Now()
+	start := now.AddDate(0, -2, 0)
+	var endedAt driver.Value
+	if status == models.GoalStatusAchieved {
+		endedAt = now.AddDate(0, 0, -3)
+	}
+	return []driver.Value{int64(3), "lose_weight", 75.0, nil, nil, nil, 85.0, nil, start, status, endedAt, start}
+}
+
+func scriptGoal(db *fakeDB, status string, currentWeight float64) {
+	db.on("FROM user_goals", []string{"id", "goal_type", "target_weight", "target_body_fat", "target_date",
+		"weekly_workout_target", "start_weight", "start_body_fat", "start_date", "status", "ended_at", "created_at"}, goalRow(status))
+	db.on("FROM progress WHERE user_id = u.id", []string{"weight", "body_fat", "profile_weight"},
+		[]driver.Value{currentWeight, nil, 85.0})
+	db.on("FROM workouts", []string{"this_week", "last_week"}, []driver.Value{int64(0), int64(0)})
+}
+
+func TestRecheckGoalReopensMissedGoal(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		weight       float64
+		wantReopened bool
+		wantAchieved bool
+	}{
+		{"achieved goal after deleting the deciding entry", models.GoalStatusAchieved, 78, true, false},
+		{"achieved goal still met", models.GoalStatusAchieved, 74.5, false, false},
+		{"active goal reached by corrected entry", models.GoalStatusActive, 75, false, true},
+		{"active goal not reached", models.GoalStatusActive, 80, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			scriptGoal(db, tt.status, tt.weight)
+
+			recheckGoal(context.Background(), 1)
+
+			reopen, reopened := db.executed("ended_at = NULL")
+			if reopened != tt.wantReopened {
+				t.Fatalf("goal reopened = %v, want %v", reopened, tt.wantReopened)
+			}
+			if reopened && (reopen.args[0] != models.GoalStatusActive || reopen.args[1] != int64(3) || reopen.args[2] != models.GoalStatusAchieved) {
+				t.Fatalf("reopen args = %v", reopen.args)
+			}
+			if _, achieved := db.executed("ended_at = NOW()"); achieved != tt.wantAchieved {
+				t.Fatalf("goal achieved = %v, want %v", achieved, tt.wantAchieved)
+			}
+		})
+	}
+}
+
+// Novo merenje iznad cilja ne vraća već ostvaren cilj
+func TestRefreshGoalKeepsAchievedGoal(t *testing.T) {
+	db := newFakeDB(t)
+	scriptGoal(db, models.GoalStatusAchieved, 78)
+
+	refreshGoal(context.Background(), 1)
+
+	if _, reopened := db.executed("UPDATE user_goals"); reopened {
+		t.Fatal("refreshGoal changed an achieved goal")
+	}
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+)
+
+// foodProbeBarcode je proizvod koji se traži pri proveri Open Food Facts API-ja
+const foodProbeBarcode = "3017620425035"
+
+// healthCheck je jedna provera zavisnosti za /health/ready
+type healthCheck struct {
+	name     string
+	critical bool
+	run      func(ctx context.Context) (detail string, err error)
+}
+
+// LiveHealth potvrđuje da proces radi i odgovara na zahteve; ne proverava zavisnosti,
+// da orkestrator ne bi restartovao server zbog pada baze
+func LiveHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeHealthReport(w, models.HealthReport{
+		Status:    "ok",
+		CheckedAt: time.Now().UTC().Format(time.RFC3339),
+		Checks:    []models.HealthCheck{},
+	})
+}
+
+// ReadyHealth proverava bazu, verziju šeme i (opciono) Open Food Facts API.
+// Vraća 503 ako kritična provera ne uspe, da orkestrator ne šalje saobraćaj serveru.
+func ReadyHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	checks := []healthCheck{
+		{name: "database", critical: true, run: checkDatabase},
+		{name: "migrations", critical: true, run: checkMigrations},
+	}
+	if settings.healthCheckFood {
+		checks = append(checks, healthCheck{name: "food_provider", run: checkFoodProvider})
+	}
+
+	report := runHealthChecks(r.Context(), checks, settings.healthCheckTimeout)
+	writeHealthReport(w, report)
+}
+
+// runHealthChecks izvršava provere paralelno; provera koja ne završi u roku se računa kao neuspela
+func runHealthChecks(ctx context.Context, checks []healthCheck, timeout time.Duration) models.HealthReport {
+	report := models.HealthReport{
+		Status:    "ok",
+		CheckedAt: time.Now().UTC().Format(time.RFC3339),
+		Checks:    make([]models.HealthCheck, len(checks)),
+	}
+
+	done := make(chan struct{}, len(checks))
+	for i, check := range checks {
+		go func(i int, check healthCheck) {
+			report.Checks[i] = runHealthCheck(ctx, check, timeout)
+			done <- struct{}{}
+		}(i, check)
+	}
+	for range checks {
+		<-done
+	}
+
+	for _, check := range report.Checks {
+		if check.Status == "ok" {
+			continue
+		}
+		if check.Critical {
+			report.Status = "fail"
+		} else if report.Status == "ok" {
+			report.Status = "degraded"
+		}
+	}
+	return report
+}
+
+// runHealthCheck izvršava jednu proveru i meri njeno trajanje. Ako provera ne završi
+// u roku, rezultat se vraća odmah, a provera se završava u pozadini.
+func runHealthCheck(ctx context.Context, check healthCheck, timeout time.Duration) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	result := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		detail, err := check.run(ctx)
+		result <- outcome{detail, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-result:
+	case <-ctx.Done():
+		out.err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	status := models.HealthCheck{
+		Name:      check.name,
+		Status:    "ok",
+		Critical:  check.critical,
+		LatencyMs: math.Round(float64(time.Since(start).Microseconds())/10) / 100,
+		Detail:    out.detail,
+	}
+	if out.err != nil {
+		status.Status = "fail"
+		status.Error = out.err.Error()
+	}
+	return status
+}
+
+// writeHealthReport upisuje izveštaj; status "fail" vraća 503
+func writeHealthReport(w http.ResponseWriter, report models.HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == "fail" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// checkDatabase proverava konekciju sa bazom
+func checkDatabase(ctx context.Context) (string, error) {
+	if utils.DB == nil {
+		return "", errors.New("database is not initialized")
+	}
+	if err := utils.DB.PingContext(ctx); err != nil {
+		return "", err
+	}
+	stats := utils.DB.Stats()
+	return fmt.Sprintf("%d open connections, %d in use", stats.OpenConnections, stats.InUse), nil
+}
+
+// checkMigrations proverava da li je šema baze ažurna u odnosu na migracije servera
+func checkMigrations(ctx context.Context) (string, error) {
+	if utils.DB == nil {
+		return "", errors.New("database is not initialized")
+	}
+	summary, err := utils.SummarizeSchema()
+	if err != nil {
+		return "", err
+	}
+
+	detail := fmt.Sprintf("version %s", summary.Current)
+	if len(summary.Missing) > 0 {
+		detail += fmt.Sprintf(" (database has newer migrations: %s)", strings.Join(summary.Missing, ", "))
+	}
+	if !summary.UpToDate() {
+		return detail, fmt.Errorf("schema is behind %s: pending [%s], modified after apply [%s]",
+			summary.Latest, strings.Join(summary.Pending, ", "), strings.Join(summary.Modified, ", "))
+	}
+	return detail, nil
+}
+
+// checkFoodProvider proverava da li Open Food Facts API odgovara
+func checkFoodProvider(ctx context.Context) (string, error) {
+	url := fmt.Sprintf("%s/api/v2/product/%s.json?fields=code", openFoodFactsURL, foodProbeBarcode)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return fmt.Sprintf("HTTP %d", resp.StatusCode), nil
+}
@@ -0,0 +1,327 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+// Dnevni cilj unosa vode: 35 ml po kg telesne težine i 12 ml po minutu treninga
+// (oko 0.35 l na pola sata), zaokruženo na 50 ml
+const (
+	waterMLPerKg            = 35.0
+	waterMLPerWorkoutMinute = 12.0
+	maxWaterLogML           = 5000
+)
+
+// GetWaterLogs vraća unose vode za jedan dan (?date=YYYY-MM-DD, podrazumevano danas)
+func GetWaterLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	day, err := parseDayParam(r)
+	if err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := utils.DB.Query(
+		"SELECT id, amount_ml, logged_at, created_at FROM water_logs WHERE user_id = ? AND logged_at >= ? AND logged_at < ? ORDER BY logged_at",
+		userID, day, day.AddDate(0, 0, 1),
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error querying water logs", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	logs := []models.WaterLog{}
+	for rows.Next() {
+		var entry models.WaterLog
+		if err := rows.Scan(&entry.ID, &entry.AmountML, &entry.LoggedAt, &entry.CreatedAt); err != nil {
+			slog.ErrorContext(r.Context(), "error scanning water log row", "error", err)
+			continue
+		}
+		logs = append(logs, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(r.Context(), "error iterating water log rows", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logs)
+}
+
+// CreateWaterLog beleži unos vode
+func CreateWaterLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.WaterLogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.AmountML <= 0 || req.AmountML > maxWaterLogML {
+		utils.JSONError(w, fmt.Sprintf("amount_ml must be between 1 and %d", maxWaterLogML), http.StatusBadRequest)
+		return
+	}
+
+	loggedAt := time.Now()
+	if req.LoggedAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *req.LoggedAt)
+		if err != nil {
+			utils.JSONError(w, "Invalid logged_at format. Use RFC3339, e.g. 2024-05-01T08:30:00+02:00", http.StatusBadRequest)
+			return
+		}
+		loggedAt = parsed.Local()
+	}
+
+	result, err := utils.DB.Exec("INSERT INTO water_logs (user_id, amount_ml, logged_at) VALUES (?, ?, ?)", userID, req.AmountML, loggedAt)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error creating water log", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to log water: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logID, _ := result.LastInsertId()
+	var entry models.WaterLog
+	err = utils.DB.QueryRow("SELECT id, amount_ml, logged_at, created_at FROM water_logs WHERE id = ?", logID).Scan(
+		&entry.ID, &entry.AmountML, &entry.LoggedAt, &entry.CreatedAt,
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching created water log", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to fetch created water log: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// DeleteWaterLog briše unos vode (?id=)
+func DeleteWaterLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	logID, _ := strconv.Atoi(r.URL.Query().Get("id"))
+
+	result, err := utils.DB.Exec("DELETE FROM water_logs WHERE id = ? AND user_id = ?", logID, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error deleting water log", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to delete water log: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.JSONError(w, "Water log not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Water log deleted successfully"})
+}
+
+// GetHydrationSummary vraća dnevni zbir unosa vode i cilj (?date=YYYY-MM-DD)
+func GetHydrationSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	day, err := parseDayParam(r)
+	if err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	days, err := hydrationDays(userID, day, day)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error building hydration summary", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(days[0])
+}
+
+// GetHydrationWeek vraća nedeljni pregled unosa vode za nedelju koja sadrži ?date= (podrazumevano tekuću)
+func GetHydrationWeek(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	day, err := parseDayParam(r)
+	if err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start, end := weekBounds(day)
+	days, err := hydrationDays(userID, start, end)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error building hydration week", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	week := models.HydrationWeek{WeekStart: start.Format("2006-01-02"), WeekEnd: end.Format("2006-01-02"), Days: days}
+	today := truncateToDay(time.Now()).Format("2006-01-02")
+	elapsed := 0
+	for _, d := range days {
+		week.TotalML += d.TotalML
+		week.TargetML += d.TargetML
+		if d.TotalML >= d.TargetML {
+			week.DaysTargetMet++
+		}
+		if d.Date <= today {
+			elapsed++
+		}
+	}
+	if elapsed > 0 {
+		week.AverageML = week.TotalML / elapsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(week)
+}
+
+// hydrationDays računa dnevne zbirove unosa vode i ciljeve za svaki dan u opsegu (uključivo)
+func hydrationDays(userID int, from, to time.Time) ([]models.HydrationDay, error) {
+	weight, err := latestWeight(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type dayTotals struct{ water, entries, minutes int }
+	totals := make(map[string]*dayTotals)
+	get := func(date string) *dayTotals {
+		if totals[date] == nil {
+			totals[date] = &dayTotals{}
+		}
+		return totals[date]
+	}
+
+	rows, err := utils.DB.Query(
+		"SELECT DATE(logged_at), SUM(amount_ml), COUNT(*) FROM water_logs WHERE user_id = ? AND logged_at >= ? AND logged_at < ? GROUP BY DATE(logged_at)",
+		userID, from, to.AddDate(0, 0, 1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum water logs: %w", err)
+	}
+	for rows.Next() {
+		var date time.Time
+		var water, entries int
+		if err := rows.Scan(&date, &water, &entries); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan water totals: %w", err)
+		}
+		day := get(date.Format("2006-01-02"))
+		day.water, day.entries = water, entries
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = utils.DB.Query(
+		"SELECT workout_date, SUM(duration) FROM workouts WHERE user_id = ? AND workout_date BETWEEN ? AND ? GROUP BY workout_date",
+		userID, from.Format("2006-01-02"), to.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum workout minutes: %w", err)
+	}
+	for rows.Next() {
+		var date time.Time
+		var minutes int
+		if err := rows.Scan(&date, &minutes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan workout minutes: %w", err)
+		}
+		get(date.Format("2006-01-02")).minutes = minutes
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var days []models.HydrationDay
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		t := get(date)
+		target := dailyWaterTarget(weight, t.minutes)
+		days = append(days, models.HydrationDay{
+			Date:           date,
+			TotalML:        t.water,
+			TargetML:       target,
+			Percent:        math.Round(float64(t.water)/float64(target)*1000) / 10,
+			Entries:        t.entries,
+			WorkoutMinutes: t.minutes,
+		})
+	}
+	return days, nil
+}
+
+// dailyWaterTarget računa dnevni cilj unosa vode u ml
+func dailyWaterTarget(weightKg float64, workoutMinutes int) int {
+	target := weightKg*waterMLPerKg + float64(workoutMinutes)*waterMLPerWorkoutMinute
+	return int(math.Round(target/50) * 50)
+}
+
+// parseDayParam čita opcioni ?date=YYYY-MM-DD, podrazumevano danas
+func parseDayParam(r *http.Request) (time.Time, error) {
+	value := r.URL.Query().Get("date")
+	if value == "" {
+		return truncateToDay(time.Now()), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid date format. Use YYYY-MM-DD")
+	}
+	return day, nil
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"backend/importers"
+	"backend/metrics"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+const (
+	// maxImportFileSize ograničava veličinu fajla koji se uvozi
+	maxImportFileSize = 25 << 20
+	// defaultImportActivity se koristi za procenu kalorija kada sport iz fajla nije prepoznat
+	defaultImportActivity = "running"
+)
+
+var errUnsupportedImportFormat = errors.New("unsupported file format, use .gpx, .tcx or .fit")
+
+// ImportWorkout uvozi trening iz GPX, TCX ili FIT fajla (multipart polje "file").
+// Opciono polje "activity_type" zamenjuje sport prepoznat iz fajla.
+func ImportWorkout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		utils.JSONError(w, "Invalid upload. Send the file as multipart form field 'file' (max 25 MB)", http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.JSONError(w, "Missing file field 'file'", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	format := strings.ToLower(r.FormValue("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+	}
+
+	activity, err := parseActivityFile(format, file)
+	if err != nil {
+		slog.WarnContext(r.Context(), "error parsing imported file", "filename", header.Filename, "error", err)
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workout, duplicate, err := saveImportedActivity(userID, activity, r.FormValue("activity_type"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error saving imported workout", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to import workout: %v", err), caloriesErrorStatus(err))
+		return
+	}
+
+	if duplicate {
+		slog.InfoContext(r.Context(), "workout already imported", "filename", header.Filename, "workout_id", workout.ID)
+	} else {
+		slog.InfoContext(r.Context(), "workout imported", "source", activity.Source, "workout_id", workout.ID)
+		refreshAchievements(r.Context(), userID)
+		refreshGoal(r.Context(), userID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !duplicate {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(models.WorkoutImportResponse{Workout: workout, Duplicate: duplicate})
+}
+
+// parseActivityFile bira parser prema formatu fajla
+func parseActivityFile(format string, file io.Reader) (*importers.Activity, error) {
+	switch format {
+	case importers.SourceGPX:
+		return importers.ParseGPX(file)
+	case importers.SourceTCX:
+		return importers.ParseTCX(file)
+	case importers.SourceFIT:
+		return importers.ParseFIT(file)
+	}
+	return nil, errUnsupportedImportFormat
+}
+
+// saveImportedActivity upisuje uvezenu aktivnost kao trening. Ako je ista aktivnost
+// već uvezena (isti otisak), vraća postojeći trening i duplicate=true.
+func saveImportedActivity(userID int, activity *importers.Activity, activityOverride string) (models.Workout, bool, error) {
+	fingerprint := activity.Fingerprint()
+
+	if existing, found, err := findImportedWorkout(userID, fingerprint); err != nil || found {
+		return existing, found, err
+	}
+
+	activityType := activityOverride
+	if activityType == "" {
+		activityType = activity.ActivityType
+	}
+	if activityType == "" {
+		activityType = defaultImportActivity
+	}
+	if _, err := activityMET(activityType); err != nil {
+		return models.Workout{}, false, err
+	}
+
+	minutes := activity.DurationSeconds / 60
+	duration := int(math.Max(1, math.Round(minutes)))
+
+	// Kalorije sa uređaja imaju prednost, inače procena iz MET vrednosti
+	var calories float64
+	method := models.CaloriesMethodDevice
+	if activity.Calories != nil {
+		calories = *activity.Calories
+	} else {
+		estimated, err := estimateCaloriesBurned(userID, activityType, math.Max(1, minutes))
+		if err != nil {
+			return models.Workout{}, false, err
+		}
+		calories, method = estimated, models.CaloriesMethodMET
+	}
+
+	name := activity.Name
+	if name == "" {
+		name = fmt.Sprintf("%s %s", strings.ToUpper(activityType[:1])+strings.ReplaceAll(activityType[1:], "_", " "),
+			activity.StartTime.Local().Format("2006-01-02 15:04"))
+	}
+
+	var distanceKm, elevation *float64
+	if activity.DistanceMeters > 0 {
+		value := math.Round(activity.DistanceMeters) / 1000
+		distanceKm = &value
+	}
+	if activity.ElevationGainM > 0 {
+		elevation = &activity.ElevationGainM
+	}
+
+	// Uzorci van fiziološkog opsega su greške senzora i preskaču se
+	samples := make([]models.HeartRateSample, 0, len(activity.HeartRate))
+	for _, sample := range activity.HeartRate {
+		if validBPM(sample.BPM) {
+			samples = append(samples, sample)
+		}
+	}
+	avgHR, maxHR := activity.AvgHeartRate, activity.MaxHeartRate
+	if len(samples) > 0 {
+		avg, max := heartRateSummary(samples)
+		avgHR, maxHR = &avg, &max
+	}
+
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		return models.Workout{}, false, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO workouts (user_id, name, activity_type, duration, calories_burned, calories_method, distance_km, elevation_gain_m, avg_heart_rate, max_heart_rate, source, started_at, import_fingerprint, workout_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		userID, name, activityType, duration, math.Round(calories*100)/100, method, distanceKm, elevation, avgHR, maxHR,
+		activity.Source, activity.StartTime, fingerprint, activity.StartTime.Local().Format("2006-01-02"),
+	)
+	if err != nil {
+		// Paralelni uvoz iste aktivnosti završava na jedinstvenom indeksu
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			tx.Rollback()
+			existing, found, findErr := findImportedWorkout(userID, fingerprint)
+			if findErr == nil && found {
+				return existing, true, nil
+			}
+		}
+		return models.Workout{}, false, fmt.Errorf("failed to insert workout: %w", err)
+	}
+
+	workoutID, _ := result.LastInsertId()
+	if err := replaceHeartRateSamples(tx, workoutID, samples); err != nil {
+		return models.Workout{}, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Workout{}, false, fmt.Errorf("failed to commit import: %w", err)
+	}
+	metrics.WorkoutsCreated.Inc("file")
+
+	workout, err := fetchWorkout(userID, workoutID)
+	return workout, false, err
+}
+
+// findImportedWorkout traži trening korisnika sa datim otiskom uvoza
+func findImportedWorkout(userID int, fingerprint string) (models.Workout, bool, error) {
+	var workoutID int64
+	err := utils.DB.QueryRow(
+		"SELECT id FROM workouts WHERE user_id = ? AND import_fingerprint = ?",
+		userID, fingerprint,
+	).Scan(&workoutID)
+	if err == sql.ErrNoRows {
+		return models.Workout{}, false, nil
+	}
+	if err != nil {
+		return models.Workout{}, false, fmt.Errorf("failed to check for duplicate import: %w", err)
+	}
+
+	workout, err := fetchWorkout(userID, workoutID)
+	return workout, err == nil, err
+}
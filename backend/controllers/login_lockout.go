@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"backend/utils"
+)
+
+// failedLoginWindow je period posle koga se brojač neuspelih prijava resetuje
+const failedLoginWindow = 24 * time.Hour
+
+// lockoutDuration vraća trajanje zaključavanja za dati broj uzastopnih neuspelih
+// prijava: osnovno trajanje pri dostizanju praga, pa duplo za svaki sledeći neuspeh
+func lockoutDuration(failures int) time.Duration {
+	if failures < settings.lockoutThreshold {
+		return 0
+	}
+	duration := settings.lockoutBase
+	for i := settings.lockoutThreshold; i < failures && duration < settings.lockoutMax; i++ {
+		duration *= 2
+	}
+	if duration > settings.lockoutMax {
+		duration = settings.lockoutMax
+	}
+	return duration
+}
+
+// recordFailedLogin beleži neuspelu prijavu i zaključava nalog kada broj uzastopnih
+// neuspeha dostigne prag. Vraća vreme do kog je nalog zaključan (nulto ako nije).
+func recordFailedLogin(userID int) (time.Time, error) {
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var failures int
+	var lastFailure sql.NullTime
+	if err := tx.QueryRow(
+		"SELECT failed_login_count, last_failed_login_at FROM users WHERE id = ? FOR UPDATE", userID,
+	).Scan(&failures, &lastFailure); err != nil {
+		return time.Time{}, fmt.Errorf("failed to load login failures: %w", err)
+	}
+
+	now := time.Now()
+	if !lastFailure.Valid || now.Sub(lastFailure.Time) > failedLoginWindow {
+		failures = 0
+	}
+	failures++
+
+	var lockedUntil time.Time
+	var lockedValue interface{}
+	if duration := lockoutDuration(failures); duration > 0 {
+		lockedUntil = now.Add(duration)
+		lockedValue = lockedUntil
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE users SET failed_login_count = ?, last_failed_login_at = ?, locked_until = COALESCE(?, locked_until) WHERE id = ?",
+		failures, now, lockedValue, userID,
+	); err != nil {
+		return time.Time{}, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit login failure: %w", err)
+	}
+	return lockedUntil, nil
+}
+
+// clearFailedLogins resetuje brojač neuspelih prijava posle uspešne prijave
+func clearFailedLogins(userID int) error {
+	_, err := utils.DB.Exec(
+		"UPDATE users SET failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL WHERE id = ? AND (failed_login_count > 0 OR locked_until IS NOT NULL)",
+		userID,
+	)
+	return err
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.lockoutThreshold = 5
+	settings.lockoutBase = time.Minute
+	settings.lockoutMax = 10 * time.Minute
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{8, 8 * time.Minute},
+		{9, 10 * time.Minute}, // 16 minuta se ograničava na lockoutMax
+		{50, 10 * time.Minute},
+		{1 << 30, 10 * time.Minute}, // petlja staje na maksimumu, bez prekoračenja
+	}
+	for _, tt := range tests {
+		if got := lockoutDuration(tt.failures); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLockoutDurationMaxEqualsBase(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { settings = saved })
+	settings.lockoutThreshold = 1
+	settings.lockoutBase = 15 * time.Minute
+	settings.lockoutMax = 15 * time.Minute
+
+	for _, failures := range []int{1, 2, 10} {
+		if got := lockoutDuration(failures); got != 15*time.Minute {
+			t.Errorf("lockoutDuration(%d) = %v, want 15m", failures, got)
+		}
+	}
+}
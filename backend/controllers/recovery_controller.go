@@ -0,0 +1,489 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+const (
+	// maxSleepHours odbacuje očigledno pogrešne unose (npr. zamenjen datum)
+	maxSleepHours = 16
+	// recommendedSleepMinutes je trajanje sna koje donosi pun broj poena
+	recommendedSleepMinutes = 8 * 60
+	// restingHRBaselineDays je period za prosečan puls u mirovanju
+	restingHRBaselineDays = 30
+	// defaultRecoveryRangeDays je podrazumevani opseg za listanje unosa
+	defaultRecoveryRangeDays = 30
+)
+
+// Težine činilaca ocene spremnosti. Činioci bez podataka se izostavljaju,
+// a preostale težine se normalizuju.
+var readinessWeights = map[string]float64{
+	"sleep":              0.40,
+	"resting_heart_rate": 0.20,
+	"soreness":           0.15,
+	"training_load":      0.25,
+}
+
+// GetSleepEntries vraća unose spavanja (?from=&to=, podrazumevano poslednjih 30 dana)
+func GetSleepEntries(w http.ResponseWriter, r *http.Request) {
+	userID, from, to, ok := parseRecoveryRange(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := utils.DB.Query(
+		"SELECT id, bed_time, wake_time, TIMESTAMPDIFF(MINUTE, bed_time, wake_time), quality, notes, sleep_date, created_at "+
+			"FROM sleep_entries WHERE user_id = ? AND sleep_date BETWEEN ? AND ? ORDER BY sleep_date DESC, wake_time DESC",
+		userID, from.Format("2006-01-02"), to.Format("2006-01-02"),
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error querying sleep entries", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	entries := []models.SleepEntry{}
+	for rows.Next() {
+		entry, err := scanSleepEntry(rows)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "error scanning sleep entry row", "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(r.Context(), "error iterating sleep entry rows", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// CreateSleepEntry beleži period spavanja
+func CreateSleepEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.SleepEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	bedTime, err := time.Parse(time.RFC3339, req.BedTime)
+	if err != nil {
+		utils.JSONError(w, "Invalid bed_time format. Use RFC3339, e.g. 2024-05-01T23:00:00+02:00", http.StatusBadRequest)
+		return
+	}
+	wakeTime, err := time.Parse(time.RFC3339, req.WakeTime)
+	if err != nil {
+		utils.JSONError(w, "Invalid wake_time format. Use RFC3339, e.g. 2024-05-02T07:00:00+02:00", http.StatusBadRequest)
+		return
+	}
+	bedTime, wakeTime = bedTime.Local(), wakeTime.Local()
+	if !wakeTime.After(bedTime) || wakeTime.Sub(bedTime) > maxSleepHours*time.Hour {
+		utils.JSONError(w, fmt.Sprintf("wake_time must be after bed_time and at most %d hours later", maxSleepHours), http.StatusBadRequest)
+		return
+	}
+	if req.Quality < 1 || req.Quality > 5 {
+		utils.JSONError(w, "quality must be between 1 and 5", http.StatusBadRequest)
+		return
+	}
+
+	result, err := utils.DB.Exec(
+		"INSERT INTO sleep_entries (user_id, bed_time, wake_time, quality, notes, sleep_date) VALUES (?, ?, ?, ?, ?, ?)",
+		userID, bedTime, wakeTime, req.Quality, nullableString(req.Notes), wakeTime.Format("2006-01-02"),
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error creating sleep entry", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to create sleep entry: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	entryID, _ := result.LastInsertId()
+	entry, err := scanSleepEntry(utils.DB.QueryRow(
+		"SELECT id, bed_time, wake_time, TIMESTAMPDIFF(MINUTE, bed_time, wake_time), quality, notes, sleep_date, created_at FROM sleep_entries WHERE id = ?",
+		entryID,
+	))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching created sleep entry", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to fetch created sleep entry: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// DeleteSleepEntry briše unos spavanja (?id=)
+func DeleteSleepEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	entryID, _ := strconv.Atoi(r.URL.Query().Get("id"))
+
+	result, err := utils.DB.Exec("DELETE FROM sleep_entries WHERE id = ? AND user_id = ?", entryID, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error deleting sleep entry", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to delete sleep entry: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.JSONError(w, "Sleep entry not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Sleep entry deleted successfully"})
+}
+
+// GetRecoveryEntries vraća dnevne unose oporavka (?from=&to=, podrazumevano poslednjih 30 dana)
+func GetRecoveryEntries(w http.ResponseWriter, r *http.Request) {
+	userID, from, to, ok := parseRecoveryRange(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := utils.DB.Query(
+		"SELECT id, entry_date, resting_heart_rate, soreness, notes, created_at, updated_at "+
+			"FROM recovery_entries WHERE user_id = ? AND entry_date BETWEEN ? AND ? ORDER BY entry_date DESC",
+		userID, from.Format("2006-01-02"), to.Format("2006-01-02"),
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error querying recovery entries", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	entries := []models.RecoveryEntry{}
+	for rows.Next() {
+		entry, err := scanRecoveryEntry(rows)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "error scanning recovery entry row", "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(r.Context(), "error iterating recovery entry rows", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// SaveRecoveryEntry upisuje puls u mirovanju i upalu mišića za dan. Postojeći unos za isti
+// dan se dopunjuje: polja koja nisu poslata zadržavaju sačuvanu vrednost.
+func SaveRecoveryEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.RecoveryEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entryDate, err := time.Parse("2006-01-02", req.EntryDate)
+	if err != nil {
+		utils.JSONError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	if req.RestingHeartRate == nil && req.Soreness == nil {
+		utils.JSONError(w, "Set resting_heart_rate and/or soreness", http.StatusBadRequest)
+		return
+	}
+	if req.RestingHeartRate != nil && !validBPM(*req.RestingHeartRate) {
+		utils.JSONError(w, "resting_heart_rate must be between 20 and 250 bpm", http.StatusBadRequest)
+		return
+	}
+	if req.Soreness != nil && (*req.Soreness < 1 || *req.Soreness > 5) {
+		utils.JSONError(w, "soreness must be between 1 and 5", http.StatusBadRequest)
+		return
+	}
+
+	_, err = utils.DB.Exec(
+		"INSERT INTO recovery_entries (user_id, entry_date, resting_heart_rate, soreness, notes) VALUES (?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE resting_heart_rate = COALESCE(VALUES(resting_heart_rate), resting_heart_rate), "+
+			"soreness = COALESCE(VALUES(soreness), soreness), notes = COALESCE(VALUES(notes), notes)",
+		userID, entryDate.Format("2006-01-02"), req.RestingHeartRate, req.Soreness, nullableString(req.Notes),
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error saving recovery entry", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to save recovery entry: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	entry, err := scanRecoveryEntry(utils.DB.QueryRow(
+		"SELECT id, entry_date, resting_heart_rate, soreness, notes, created_at, updated_at FROM recovery_entries WHERE user_id = ? AND entry_date = ?",
+		userID, entryDate.Format("2006-01-02"),
+	))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching saved recovery entry", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to fetch recovery entry: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// GetReadiness vraća ocenu spremnosti za trening za dan (?date=YYYY-MM-DD, podrazumevano danas).
+// Ocena kombinuje san prethodne noći, puls u mirovanju u odnosu na prosek, upalu mišića
+// i odnos opterećenja u poslednjih 7 dana prema proseku poslednjih 28 dana.
+func GetReadiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	day, err := parseDayParam(r)
+	if err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	readiness, err := computeReadiness(userID, day)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error computing readiness", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(readiness)
+}
+
+// computeReadiness učitava podatke za dan i računa ocenu spremnosti
+func computeReadiness(userID int, day time.Time) (models.Readiness, error) {
+	readiness := models.Readiness{Date: day.Format("2006-01-02"), Components: []models.ReadinessComponent{}}
+	date := day.Format("2006-01-02")
+
+	var sleepMinutes, sleepCount int
+	var sleepQuality, baselineHR sql.NullFloat64
+	var restingHR, soreness sql.NullInt64
+	err := utils.DB.QueryRow(
+		"SELECT "+
+			"(SELECT COALESCE(SUM(TIMESTAMPDIFF(MINUTE, bed_time, wake_time)), 0) FROM sleep_entries WHERE user_id = u.id AND sleep_date = ?), "+
+			"(SELECT AVG(quality) FROM sleep_entries WHERE user_id = u.id AND sleep_date = ?), "+
+			"(SELECT COUNT(*) FROM sleep_entries WHERE user_id = u.id AND sleep_date = ?), "+
+			"(SELECT resting_heart_rate FROM recovery_entries WHERE user_id = u.id AND entry_date = ?), "+
+			"(SELECT soreness FROM recovery_entries WHERE user_id = u.id AND entry_date = ?), "+
+			"(SELECT AVG(resting_heart_rate) FROM recovery_entries WHERE user_id = u.id AND entry_date >= ? AND entry_date < ?) "+
+			"FROM users u WHERE u.id = ?",
+		date, date, date, date, date, day.AddDate(0, 0, -restingHRBaselineDays).Format("2006-01-02"), date, userID,
+	).Scan(&sleepMinutes, &sleepQuality, &sleepCount, &restingHR, &soreness, &baselineHR)
+	if err != nil {
+		return readiness, fmt.Errorf("failed to load recovery data: %w", err)
+	}
+
+	acute, chronic, err := trainingLoadMinutes(userID, day)
+	if err != nil {
+		return readiness, err
+	}
+	readiness.AcuteLoadMinutes = acute
+	readiness.ChronicLoadMinutes = math.Round(chronic*10) / 10
+
+	add := func(name string, score float64) {
+		readiness.Components = append(readiness.Components, models.ReadinessComponent{
+			Name: name, Score: math.Round(score*1000) / 10, Weight: readinessWeights[name],
+		})
+	}
+
+	if sleepCount > 0 {
+		readiness.SleepMinutes = &sleepMinutes
+		quality := math.Round(sleepQuality.Float64*10) / 10
+		readiness.SleepQuality = &quality
+		add("sleep", sleepScore(sleepMinutes, sleepQuality.Float64))
+	}
+	if restingHR.Valid {
+		value := int(restingHR.Int64)
+		readiness.RestingHeartRate = &value
+		if baselineHR.Valid {
+			baseline := math.Round(baselineHR.Float64*10) / 10
+			readiness.BaselineRestingHR = &baseline
+			add("resting_heart_rate", restingHRScore(value, baselineHR.Float64))
+		}
+	}
+	if soreness.Valid {
+		value := int(soreness.Int64)
+		readiness.Soreness = &value
+		add("soreness", float64(5-value)/4)
+	}
+	ratio, loadScore := trainingLoadScore(float64(acute), chronic)
+	readiness.LoadRatio = ratio
+	add("training_load", loadScore)
+
+	// Samo opterećenje nije dovoljno za ocenu; potreban je bar jedan subjektivni ili izmereni podatak
+	if len(readiness.Components) == 1 {
+		readiness.Level = "unknown"
+		return readiness, nil
+	}
+
+	var total, weights float64
+	for _, component := range readiness.Components {
+		total += component.Score * component.Weight
+		weights += component.Weight
+	}
+	score := int(math.Round(total / weights))
+	readiness.Score = &score
+	switch {
+	case score >= 75:
+		readiness.Level = "high"
+	case score >= 50:
+		readiness.Level = "moderate"
+	default:
+		readiness.Level = "low"
+	}
+	return readiness, nil
+}
+
+// trainingLoadMinutes vraća minute treninga u 7 dana pre datog dana (akutno opterećenje)
+// i nedeljni prosek 28 dana pre datog dana (hronično opterećenje)
+func trainingLoadMinutes(userID int, day time.Time) (int, float64, error) {
+	var acute, chronic int
+	err := utils.DB.QueryRow(
+		"SELECT COALESCE(SUM(CASE WHEN workout_date >= ? THEN duration END), 0), COALESCE(SUM(duration), 0) "+
+			"FROM workouts WHERE user_id = ? AND workout_date >= ? AND workout_date < ?",
+		day.AddDate(0, 0, -7).Format("2006-01-02"), userID, day.AddDate(0, 0, -28).Format("2006-01-02"), day.Format("2006-01-02"),
+	).Scan(&acute, &chronic)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load training load: %w", err)
+	}
+	return acute, float64(chronic) / 4, nil
+}
+
+// sleepScore (0-1): 70% trajanje u odnosu na preporučenih 8 sati, 30% kvalitet
+func sleepScore(minutes int, quality float64) float64 {
+	duration := math.Min(1, float64(minutes)/recommendedSleepMinutes)
+	return 0.7*duration + 0.3*(quality-1)/4
+}
+
+// restingHRScore (0-1): puls na nivou proseka ili niži daje pun broj poena,
+// a svaki otkucaj iznad proseka oduzima 10%
+func restingHRScore(restingHR int, baseline float64) float64 {
+	return math.Max(0, math.Min(1, 1-(float64(restingHR)-baseline)/10))
+}
+
+// trainingLoadScore (0-1) na osnovu odnosa akutnog i hroničnog opterećenja:
+// do 1.0 pun broj poena, do 1.5 pada na 0.5, a na 2.0 i više je 0
+func trainingLoadScore(acute, chronic float64) (*float64, float64) {
+	if chronic == 0 {
+		if acute == 0 {
+			return nil, 1
+		}
+		return nil, 0.7
+	}
+	ratio := math.Round(acute/chronic*100) / 100
+	switch {
+	case ratio <= 1:
+		return &ratio, 1
+	case ratio <= 1.5:
+		return &ratio, 1 - (ratio - 1)
+	default:
+		return &ratio, math.Max(0, 0.5-(ratio-1.5))
+	}
+}
+
+// parseRecoveryRange proverava metod i korisnika i čita ?from=&to= (podrazumevano poslednjih 30 dana)
+func parseRecoveryRange(w http.ResponseWriter, r *http.Request) (int, time.Time, time.Time, bool) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return 0, time.Time{}, time.Time{}, false
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, time.Time{}, time.Time{}, false
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		utils.JSONError(w, err.Error(), http.StatusBadRequest)
+		return 0, time.Time{}, time.Time{}, false
+	}
+	end := truncateToDay(time.Now())
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(0, 0, -(defaultRecoveryRangeDays - 1))
+	if from != nil {
+		start = *from
+	}
+	return userID, start, end, true
+}
+
+func scanSleepEntry(row rowScanner) (models.SleepEntry, error) {
+	var entry models.SleepEntry
+	var notes sql.NullString
+	var sleepDate time.Time
+	err := row.Scan(&entry.ID, &entry.BedTime, &entry.WakeTime, &entry.DurationMinutes, &entry.Quality, &notes, &sleepDate, &entry.CreatedAt)
+	entry.Notes = notes.String
+	entry.SleepDate = sleepDate.Format("2006-01-02")
+	return entry, err
+}
+
+func scanRecoveryEntry(row rowScanner) (models.RecoveryEntry, error) {
+	var entry models.RecoveryEntry
+	var restingHR, soreness sql.NullInt64
+	var notes sql.NullString
+	var entryDate time.Time
+	err := row.Scan(&entry.ID, &entryDate, &restingHR, &soreness, &notes, &entry.CreatedAt, &entry.UpdatedAt)
+	if restingHR.Valid {
+		value := int(restingHR.Int64)
+		entry.RestingHeartRate = &value
+	}
+	if soreness.Valid {
+		value := int(soreness.Int64)
+		entry.Soreness = &value
+	}
+	entry.Notes = notes.String
+	entry.EntryDate = entryDate.Format("2006-01-02")
+	return entry, err
+}
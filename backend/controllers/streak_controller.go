@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+const (
+	// defaultAdherenceWeeks je broj nedelja u pregledu doslednosti
+	defaultAdherenceWeeks = 12
+	maxAdherenceWeeks     = 52
+	// heatmapDays je broj dana u kalendarskom prikazu (godinu dana unazad, uključujući danas)
+	heatmapDays = 365
+	// maxWeeklyWorkoutTarget je najveći dozvoljeni nedeljni cilj
+	maxWeeklyWorkoutTarget = 14
+)
+
+// trainingDay je zbir treninga jednog dana
+type trainingDay struct {
+	date     time.Time
+	workouts int
+	minutes  int
+}
+
+// GetStreaks vraća nizove treninga, nedeljnu doslednost (?weeks=12) i kalendarski prikaz za poslednju godinu
+func GetStreaks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	weeks := defaultAdherenceWeeks
+	if value := r.URL.Query().Get("weeks"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxAdherenceWeeks {
+			utils.JSONError(w, fmt.Sprintf("weeks must be between 1 and %d", maxAdherenceWeeks), http.StatusBadRequest)
+			return
+		}
+		weeks = parsed
+	}
+
+	var target sql.NullInt64
+	err := utils.DB.QueryRow("SELECT weekly_workout_target FROM users WHERE id = ?", userID).Scan(&target)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching weekly target", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	today := truncateToDay(time.Now())
+	days, err := loadTrainingDays(userID, today)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching training days", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	summary := models.StreakSummary{}
+	summary.CurrentDailyStreak, summary.LongestDailyStreak = dailyStreaks(days, today)
+	summary.CurrentWeeklyStreak, summary.LongestWeeklyStreak = weeklyStreaks(days, today)
+	if target.Valid {
+		value := int(target.Int64)
+		summary.WeeklyTarget = &value
+	}
+	summary.Weeks, summary.AdherenceRate = weeklyAdherence(days, today, weeks, summary.WeeklyTarget)
+	summary.Heatmap = activityHeatmap(days, today)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// loadTrainingDays učitava broj treninga i minute po danu, hronološki, do datog dana
+func loadTrainingDays(userID int, until time.Time) ([]trainingDay, error) {
+	rows, err := utils.DB.Query(
+		"SELECT workout_date, COUNT(*), COALESCE(SUM(duration), 0) FROM workouts WHERE user_id = ? AND workout_date <= ? GROUP BY workout_date ORDER BY workout_date",
+		userID, until.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []trainingDay
+	for rows.Next() {
+		var day trainingDay
+		if err := rows.Scan(&day.date, &day.workouts, &day.minutes); err != nil {
+			return nil, err
+		}
+		day.date = truncateToDay(day.date)
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
+
+// dailyStreaks vraća trenutni i najduži niz uzastopnih dana sa treningom.
+// Trenutni niz ostaje živ ako je poslednji trening bio juče.
+func dailyStreaks(days []trainingDay, today time.Time) (int, int) {
+	dates := make([]time.Time, len(days))
+	for i, day := range days {
+		dates[i] = day.date
+	}
+	return consecutiveRuns(dates, today, 1)
+}
+
+// weeklyStreaks vraća trenutni i najduži niz uzastopnih nedelja sa bar jednim treningom.
+// Tekuća nedelja bez treninga ne prekida niz dok ne prođe.
+func weeklyStreaks(days []trainingDay, today time.Time) (int, int) {
+	var starts []time.Time
+	for _, day := range days {
+		start, _ := weekBounds(day.date)
+		if len(starts) == 0 || !starts[len(starts)-1].Equal(start) {
+			starts = append(starts, start)
+		}
+	}
+	currentWeek, _ := weekBounds(today)
+	return consecutiveRuns(starts, currentWeek, 7)
+}
+
+// consecutiveRuns broji nizove rastućih datuma sa razmakom od step dana.
+// Trenutni niz je onaj koji se završava na latest ili jedan korak pre njega.
+func consecutiveRuns(dates []time.Time, latest time.Time, step int) (int, int) {
+	if len(dates) == 0 {
+		return 0, 0
+	}
+
+	run, longest := 1, 1
+	for i := 1; i < len(dates); i++ {
+		if dates[i].Equal(dates[i-1].AddDate(0, 0, step)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	last := dates[len(dates)-1]
+	if last.Equal(latest) || last.Equal(latest.AddDate(0, 0, -step)) {
+		return run, longest
+	}
+	return 0, longest
+}
+
+// weeklyAdherence sabira treninge po nedeljama za poslednjih n nedelja (uključujući tekuću)
+// i računa udeo završenih nedelja u kojima je ispunjen nedeljni cilj
+func weeklyAdherence(days []trainingDay, today time.Time, n int, target *int) ([]models.WeekAdherence, *float64) {
+	currentWeek, _ := weekBounds(today)
+	firstWeek := currentWeek.AddDate(0, 0, -7*(n-1))
+
+	weeks := make([]models.WeekAdherence, n)
+	index := make(map[string]int, n)
+	for i := range weeks {
+		start := firstWeek.AddDate(0, 0, 7*i)
+		weeks[i] = models.WeekAdherence{WeekStart: start.Format("2006-01-02"), Complete: start.Before(currentWeek)}
+		index[weeks[i].WeekStart] = i
+	}
+
+	for _, day := range days {
+		start, _ := weekBounds(day.date)
+		if i, ok := index[start.Format("2006-01-02")]; ok {
+			weeks[i].Workouts += day.workouts
+			weeks[i].Minutes += day.minutes
+		}
+	}
+
+	if target == nil {
+		return weeks, nil
+	}
+
+	met, complete := 0, 0
+	for i := range weeks {
+		reached := weeks[i].Workouts >= *target
+		weeks[i].TargetMet = &reached
+		if weeks[i].Complete {
+			complete++
+			if reached {
+				met++
+			}
+		}
+	}
+	if complete == 0 {
+		return weeks, nil
+	}
+	rate := math.Round(float64(met)/float64(complete)*100) / 100
+	return weeks, &rate
+}
+
+// activityHeatmap vraća po jedan unos za svaki dan poslednje godine, uključujući dane bez treninga
+func activityHeatmap(days []trainingDay, today time.Time) []models.HeatmapDay {
+	byDate := make(map[string]trainingDay, len(days))
+	for _, day := range days {
+		byDate[day.date.Format("2006-01-02")] = day
+	}
+
+	heatmap := make([]models.HeatmapDay, heatmapDays)
+	first := today.AddDate(0, 0, -(heatmapDays - 1))
+	for i := range heatmap {
+		date := first.AddDate(0, 0, i).Format("2006-01-02")
+		day := byDate[date]
+		heatmap[i] = models.HeatmapDay{Date: date, Workouts: day.workouts, Minutes: day.minutes}
+	}
+	return heatmap
+}
+
+// truncateToDay vraća ponoć istog kalendarskog dana u lokalnoj zoni
+func truncateToDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+const (
+	defaultLoadWeeks = 8
+	maxLoadWeeks     = 26
+	// defaultSessionRPE se koristi za treninge bez unetog intenziteta
+	defaultSessionRPE = 5
+	// volumeBaselineWeeks je broj prethodnih nedelja sa kojima se poredi obim mišićne grupe
+	volumeBaselineWeeks = 4
+	// volumeSpikeRatio i minVolumeSpikeSets određuju kada je porast broja serija nagao
+	volumeSpikeRatio   = 1.5
+	minVolumeSpikeSets = 4
+	// Granice za odnos akutnog i hroničnog opterećenja (ACWR)
+	acwrLow      = 0.8
+	acwrOptimal  = 1.3
+	acwrElevated = 1.5
+)
+
+// GetTrainingLoad vraća nedeljni obim po mišićnim grupama (?weeks=8), odnos akutnog
+// i hroničnog opterećenja (trajanje × intenzitet) i upozorenja na nagle skokove
+func GetTrainingLoad(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	weeks := defaultLoadWeeks
+	if value := r.URL.Query().Get("weeks"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxLoadWeeks {
+			utils.JSONError(w, fmt.Sprintf("weeks must be between 1 and %d", maxLoadWeeks), http.StatusBadRequest)
+			return
+		}
+		weeks = parsed
+	}
+
+	report, err := buildTrainingLoadReport(userID, truncateToDay(time.Now()), weeks)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error building training load report", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// buildTrainingLoadReport učitava istoriju treninga i računa pregled opterećenja.
+// Pored traženih nedelja učitava se još nekoliko prethodnih, kao osnova za poređenje obima.
+func buildTrainingLoadReport(userID int, today time.Time, weeks int) (models.TrainingLoadReport, error) {
+	report := models.TrainingLoadReport{Warnings: []models.TrainingLoadWarning{}}
+
+	currentWeek, _ := weekBounds(today)
+	// +1 nedelja jer se upozorenja proveravaju i za tekuću i za prethodnu nedelju
+	totalWeeks := weeks + volumeBaselineWeeks + 1
+	firstWeek := currentWeek.AddDate(0, 0, -7*(totalWeeks-1))
+	chronicStart := today.AddDate(0, 0, -27)
+	from := firstWeek
+	if chronicStart.Before(from) {
+		from = chronicStart
+	}
+
+	history := make([]models.TrainingLoadWeek, totalWeeks)
+	muscles := make([]map[string]*models.MuscleGroupVolume, totalWeeks)
+	for i := range history {
+		history[i] = models.TrainingLoadWeek{WeekStart: firstWeek.AddDate(0, 0, 7*i).Format("2006-01-02")}
+		muscles[i] = make(map[string]*models.MuscleGroupVolume)
+	}
+	weekIndex := func(day time.Time) int {
+		start, _ := weekBounds(day)
+		return int(math.Round(start.Sub(firstWeek).Hours() / 24 / 7))
+	}
+
+	rows, err := utils.DB.Query(
+		"SELECT workout_date, COUNT(*), SUM(duration * COALESCE(intensity, ?)) FROM workouts "+
+			"WHERE user_id = ? AND workout_date BETWEEN ? AND ? GROUP BY workout_date",
+		defaultSessionRPE, userID, from.Format("2006-01-02"), today.Format("2006-01-02"),
+	)
+	if err != nil {
+		return report, fmt.Errorf("failed to load workout load: %w", err)
+	}
+	for rows.Next() {
+		var day time.Time
+		var count int
+		var load float64
+		if err := rows.Scan(&day, &count, &load); err != nil {
+			rows.Close()
+			return report, fmt.Errorf("failed to scan workout load: %w", err)
+		}
+		day = truncateToDay(day)
+		if !day.Before(today.AddDate(0, 0, -6)) {
+			report.AcuteLoad += load
+		}
+		if !day.Before(chronicStart) {
+			report.ChronicLoad += load
+		}
+		if i := weekIndex(day); i >= 0 && i < totalWeeks {
+			history[i].Load += load
+			history[i].Workouts += count
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	rows, err = utils.DB.Query(
+		"SELECT w.workout_date, e.muscle_group, SUM(e.sets), SUM(e.sets * e.reps), SUM(e.sets * e.reps * COALESCE(e.weight_kg, 0)) "+
+			"FROM workout_exercises e JOIN workouts w ON w.id = e.workout_id "+
+			"WHERE w.user_id = ? AND w.workout_date BETWEEN ? AND ? GROUP BY w.workout_date, e.muscle_group",
+		userID, firstWeek.Format("2006-01-02"), today.Format("2006-01-02"),
+	)
+	if err != nil {
+		return report, fmt.Errorf("failed to load exercise volume: %w", err)
+	}
+	for rows.Next() {
+		var day time.Time
+		var group string
+		var sets, reps int
+		var tonnage float64
+		if err := rows.Scan(&day, &group, &sets, &reps, &tonnage); err != nil {
+			rows.Close()
+			return report, fmt.Errorf("failed to scan exercise volume: %w", err)
+		}
+		i := weekIndex(truncateToDay(day))
+		if i < 0 || i >= totalWeeks {
+			continue
+		}
+		volume := muscles[i][group]
+		if volume == nil {
+			volume = &models.MuscleGroupVolume{MuscleGroup: group}
+			muscles[i][group] = volume
+		}
+		volume.Sets += sets
+		volume.Reps += reps
+		volume.TonnageKg += tonnage
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	for i := range history {
+		history[i].Load = math.Round(history[i].Load)
+		history[i].MuscleGroups = []models.MuscleGroupVolume{}
+		for _, volume := range muscles[i] {
+			volume.TonnageKg = math.Round(volume.TonnageKg*10) / 10
+			history[i].MuscleGroups = append(history[i].MuscleGroups, *volume)
+		}
+		sort.Slice(history[i].MuscleGroups, func(a, b int) bool {
+			return history[i].MuscleGroups[a].MuscleGroup < history[i].MuscleGroups[b].MuscleGroup
+		})
+	}
+
+	report.AcuteLoad = math.Round(report.AcuteLoad)
+	report.ChronicLoad = math.Round(report.ChronicLoad/4*10) / 10
+	report.ACWRZone = "unknown"
+	if report.ChronicLoad > 0 {
+		acwr := math.Round(report.AcuteLoad/report.ChronicLoad*100) / 100
+		report.ACWR = &acwr
+		report.ACWRZone = acwrZone(acwr)
+		if acwr > acwrElevated {
+			report.Warnings = append(report.Warnings, models.TrainingLoadWarning{
+				Type:    "load_spike",
+				Message: fmt.Sprintf("Training load over the last 7 days is %.2f× your 4-week weekly average; consider a lighter week", acwr),
+			})
+		}
+	}
+
+	// Obim po mišićnim grupama za prethodnu i tekuću nedelju poredi se sa prosekom 4 nedelje pre njih
+	for i := totalWeeks - 2; i < totalWeeks; i++ {
+		report.Warnings = append(report.Warnings, volumeSpikes(muscles, i, history[i].WeekStart)...)
+	}
+
+	report.Weeks = history[totalWeeks-weeks:]
+	return report, nil
+}
+
+// volumeSpikes vraća upozorenja za mišićne grupe čiji je broj serija u nedelji i
+// naglo porastao u odnosu na prosek prethodnih nedelja
+func volumeSpikes(muscles []map[string]*models.MuscleGroupVolume, i int, weekStart string) []models.TrainingLoadWarning {
+	var warnings []models.TrainingLoadWarning
+	groups := make([]string, 0, len(muscles[i]))
+	for group := range muscles[i] {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		sets := float64(muscles[i][group].Sets)
+		baseline := 0.0
+		for j := i - volumeBaselineWeeks; j < i; j++ {
+			if volume := muscles[j][group]; volume != nil {
+				baseline += float64(volume.Sets)
+			}
+		}
+		baseline /= volumeBaselineWeeks
+		if baseline > 0 && sets >= baseline*volumeSpikeRatio && sets-baseline >= minVolumeSpikeSets {
+			warnings = append(warnings, models.TrainingLoadWarning{
+				Type:        "volume_spike",
+				MuscleGroup: group,
+				Message: fmt.Sprintf("%s: %d sets in the week of %s vs. %.1f per week over the previous %d weeks",
+					group, int(sets), weekStart, baseline, volumeBaselineWeeks),
+			})
+		}
+	}
+	return warnings
+}
+
+// acwrZone svrstava odnos akutnog i hroničnog opterećenja u zonu
+func acwrZone(acwr float64) string {
+	switch {
+	case acwr < acwrLow:
+		return "low"
+	case acwr <= acwrOptimal:
+		return "optimal"
+	case acwr <= acwrElevated:
+		return "elevated"
+	default:
+		return "high"
+	}
+}
@@ -0,0 +1,497 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/auth"
+	"backend/metrics"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+// twoFactorRoles su uloge koje mogu da uključe dvofaktorsku autentifikaciju
+var twoFactorRoles = map[string]bool{"premium": true, "admin": true}
+
+var (
+	errInvalidSecondFactor = errors.New("invalid verification code")
+	errTwoFactorDisabled   = errors.New("two-factor authentication is not enabled")
+	errChallengeUsed       = errors.New("challenge token was already used or replaced")
+)
+
+// LoginTwoFactor završava prijavu naloga sa uključenim 2FA: prima challenge token
+// iz Login i TOTP ili rezervni kod, i vraća pristupni token
+func LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		utils.JSONError(w, "challenge_token and code are required", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := auth.ValidateChallengeToken(req.ChallengeToken)
+	if err != nil {
+		utils.JSONError(w, "Invalid or expired challenge token, log in again", http.StatusUnauthorized)
+		return
+	}
+	userID := claims.UserID
+
+	if !allowSecondFactor(w, r, userID) {
+		return
+	}
+
+	var lockedUntil sql.NullTime
+	err = utils.DB.QueryRow("SELECT locked_until FROM users WHERE id = ?", userID).Scan(&lockedUntil)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "Invalid or expired challenge token, log in again", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching user for two-factor login", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	// Zaključan nalog dobija isti odgovor kao pogrešan kod (videti Login)
+	if lockedUntil.Valid && time.Now().Before(lockedUntil.Time) {
+		metrics.Logins.Inc("locked")
+		utils.JSONError(w, "Invalid verification code", http.StatusUnauthorized)
+		return
+	}
+
+	method, err := verifySecondFactor(userID, claims.ID, req.Code)
+	switch {
+	case errors.Is(err, errInvalidSecondFactor):
+		metrics.Logins.Inc("failure")
+		if _, err := recordFailedLogin(userID); err != nil {
+			slog.ErrorContext(r.Context(), "error recording failed login", "error", err)
+		}
+		utils.JSONError(w, "Invalid verification code", http.StatusUnauthorized)
+		return
+	case errors.Is(err, errTwoFactorDisabled), errors.Is(err, errChallengeUsed):
+		utils.JSONError(w, "Invalid or expired challenge token, log in again", http.StatusUnauthorized)
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "error verifying second factor", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if err := clearFailedLogins(userID); err != nil {
+		slog.ErrorContext(r.Context(), "error clearing failed logins", "error", err)
+	}
+	if method == "recovery_code" {
+		slog.InfoContext(r.Context(), "two-factor login with recovery code", "login_user_id", userID)
+	}
+
+	user, err := loadProfile(userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error loading profile after two-factor login", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	token, err := auth.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		utils.JSONError(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+	metrics.Logins.Inc("success")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.LoginResponse{User: &user, Token: token})
+}
+
+// GetTwoFactorStatus vraća da li je 2FA uključen i koliko je rezervnih kodova preostalo
+func GetTwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var status models.TwoFactorStatus
+	var role string
+	err := utils.DB.QueryRow(
+		"SELECT role, totp_enabled, totp_secret IS NOT NULL AND NOT totp_enabled, "+
+			"(SELECT COUNT(*) FROM two_factor_recovery_codes c WHERE c.user_id = u.id AND c.used_at IS NULL) "+
+			"FROM users u WHERE u.id = ?",
+		userID,
+	).Scan(&role, &status.Enabled, &status.Pending, &status.RecoveryCodesRemaining)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching two-factor status", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	status.Available = twoFactorRoles[role]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// SetupTwoFactor generiše novu TOTP tajnu i otpauth URI za QR kod. 2FA se uključuje
+// tek kada korisnik potvrdi prvi kod preko EnableTwoFactor.
+func SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var email, role string
+	var enabled bool
+	err := utils.DB.QueryRow("SELECT email, role, totp_enabled FROM users WHERE id = ?", userID).Scan(&email, &role, &enabled)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching user for two-factor setup", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !twoFactorRoles[role] {
+		utils.JSONError(w, "Two-factor authentication is available for premium and admin accounts", http.StatusForbidden)
+		return
+	}
+	if enabled {
+		utils.JSONError(w, "Two-factor authentication is already enabled", http.StatusConflict)
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error generating TOTP secret", "error", err)
+		utils.JSONError(w, "Failed to generate secret", http.StatusInternalServerError)
+		return
+	}
+	if _, err := utils.DB.Exec(
+		"UPDATE users SET totp_secret = ?, totp_last_step = NULL WHERE id = ? AND totp_enabled = FALSE",
+		secret, userID,
+	); err != nil {
+		slog.ErrorContext(r.Context(), "error saving TOTP secret", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(settings.totpIssuer, email, secret),
+	})
+}
+
+// EnableTwoFactor uključuje 2FA kada korisnik potvrdi kod iz authenticator aplikacije
+// i vraća rezervne kodove, koji se prikazuju samo jednom
+func EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		utils.JSONError(w, "code is required", http.StatusBadRequest)
+		return
+	}
+	if !allowSecondFactor(w, r, userID) {
+		return
+	}
+
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error starting transaction", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var role string
+	var secret sql.NullString
+	var enabled bool
+	err = tx.QueryRow("SELECT role, totp_secret, totp_enabled FROM users WHERE id = ? FOR UPDATE", userID).Scan(&role, &secret, &enabled)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching user for enabling two-factor", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !twoFactorRoles[role] {
+		utils.JSONError(w, "Two-factor authentication is available for premium and admin accounts", http.StatusForbidden)
+		return
+	}
+	if enabled {
+		utils.JSONError(w, "Two-factor authentication is already enabled", http.StatusConflict)
+		return
+	}
+	if !secret.Valid {
+		utils.JSONError(w, "Start two-factor setup first", http.StatusBadRequest)
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(secret.String, req.Code, time.Now(), 0)
+	if !ok {
+		utils.JSONError(w, "Invalid verification code; check that the time on your device is correct", http.StatusBadRequest)
+		return
+	}
+	if _, err := tx.Exec("UPDATE users SET totp_enabled = TRUE, totp_last_step = ? WHERE id = ?", step, userID); err != nil {
+		slog.ErrorContext(r.Context(), "error enabling two-factor", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error creating recovery codes", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		slog.ErrorContext(r.Context(), "error committing two-factor enrolment", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "two-factor authentication enabled")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TwoFactorRecoveryCodes{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor isključuje 2FA uz ponovnu potvrdu lozinkom i kodom
+func DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TwoFactorDisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Password == "" || req.Code == "" {
+		utils.JSONError(w, "password and code are required", http.StatusBadRequest)
+		return
+	}
+	if !allowSecondFactor(w, r, userID) {
+		return
+	}
+
+	var passwordHash sql.NullString
+	err := utils.DB.QueryRow("SELECT password FROM users WHERE id = ?", userID).Scan(&passwordHash)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching user for disabling two-factor", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	// 403 umesto 401, jer bi 401 odjavio korisnika u frontend-u
+	if !passwordHash.Valid || !auth.CheckPassword(req.Password, passwordHash.String) {
+		utils.JSONError(w, "Invalid password", http.StatusForbidden)
+		return
+	}
+
+	if _, err := verifySecondFactor(userID, "", req.Code); !respondSecondFactorError(w, r, err) {
+		return
+	}
+	if err := utils.ClearTwoFactor(userID); err != nil {
+		slog.ErrorContext(r.Context(), "error disabling two-factor", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "two-factor authentication disabled")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes poništava postojeće rezervne kodove i izdaje nove
+func RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		utils.JSONError(w, "code is required", http.StatusBadRequest)
+		return
+	}
+	if !allowSecondFactor(w, r, userID) {
+		return
+	}
+
+	if _, err := verifySecondFactor(userID, "", req.Code); !respondSecondFactorError(w, r, err) {
+		return
+	}
+
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error starting transaction", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error regenerating recovery codes", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "recovery codes regenerated")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TwoFactorRecoveryCodes{RecoveryCodes: codes})
+}
+
+// allowSecondFactor ograničava pokušaje unosa koda po nalogu, jer šestocifreni kod
+// bez ograničenja može da se pogodi; odgovara sa 429 i vraća false kada je limit dostignut
+func allowSecondFactor(w http.ResponseWriter, r *http.Request, userID int) bool {
+	if ok, wait := settings.loginLimiter.Allow(fmt.Sprintf("2fa:%d", userID)); !ok {
+		middleware.TooManyRequests(w, r, "2fa", wait)
+		return false
+	}
+	return true
+}
+
+// respondSecondFactorError šalje odgovor za grešku iz verifySecondFactor prijavljenom
+// korisniku (pogrešan kod je 403, da ga frontend ne bi odjavio); vraća true ako greške
+// nema i obrada zahteva može da se nastavi
+func respondSecondFactorError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errInvalidSecondFactor):
+		utils.JSONError(w, "Invalid verification code", http.StatusForbidden)
+	case errors.Is(err, errTwoFactorDisabled):
+		utils.JSONError(w, "Two-factor authentication is not enabled", http.StatusConflict)
+	default:
+		slog.ErrorContext(r.Context(), "error verifying second factor", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+	}
+	return false
+}
+
+// verifySecondFactor proverava TOTP kod ili neiskorišćen rezervni kod korisnika sa
+// uključenim 2FA i vraća način provere ("totp" ili "recovery_code"). Iskorišćeni TOTP
+// korak i rezervni kod beleže se u istoj transakciji, pa isti kod ne prolazi dva puta.
+// Pri prijavi challengeID je jti challenge tokena: mora biti poslednji izdat i troši se
+// uspešnom proverom. Prijavljeni korisnik (isključivanje 2FA, novi kodovi) šalje "".
+func verifySecondFactor(userID int, challengeID, code string) (string, error) {
+	tx, err := utils.DB.Begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var secret sql.NullString
+	var enabled bool
+	var lastStep sql.NullInt64
+	var storedChallenge sql.NullString
+	if err := tx.QueryRow(
+		"SELECT totp_secret, totp_enabled, totp_last_step, totp_challenge_id FROM users WHERE id = ? FOR UPDATE", userID,
+	).Scan(&secret, &enabled, &lastStep, &storedChallenge); err != nil {
+		return "", fmt.Errorf("failed to load two-factor settings: %w", err)
+	}
+	if !enabled || !secret.Valid {
+		return "", errTwoFactorDisabled
+	}
+	if challengeID != "" && (!storedChallenge.Valid || storedChallenge.String != challengeID) {
+		return "", errChallengeUsed
+	}
+
+	method := "totp"
+	if step, ok := auth.ValidateTOTP(secret.String, code, time.Now(), lastStep.Int64); ok {
+		if _, err := tx.Exec("UPDATE users SET totp_last_step = ? WHERE id = ?", step, userID); err != nil {
+			return "", fmt.Errorf("failed to save TOTP step: %w", err)
+		}
+	} else {
+		result, err := tx.Exec(
+			"UPDATE two_factor_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+			time.Now(), userID, auth.HashRecoveryCode(code),
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to use recovery code: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return "", errInvalidSecondFactor
+		}
+		method = "recovery_code"
+	}
+	if challengeID != "" {
+		if _, err := tx.Exec("UPDATE users SET totp_challenge_id = NULL WHERE id = ?", userID); err != nil {
+			return "", fmt.Errorf("failed to consume challenge token: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit second factor: %w", err)
+	}
+	return method, nil
+}
+
+// replaceRecoveryCodes briše postojeće rezervne kodove korisnika i upisuje heševe novih
+func replaceRecoveryCodes(tx *sql.Tx, userID int) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM two_factor_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("(?, ?),", len(codes)), ",")
+	args := make([]interface{}, 0, len(codes)*2)
+	for _, code := range codes {
+		args = append(args, userID, auth.HashRecoveryCode(code))
+	}
+	if _, err := tx.Exec("INSERT INTO two_factor_recovery_codes (user_id, code_hash) VALUES "+placeholders, args...); err != nil {
+		return nil, fmt.Errorf("failed to insert recovery codes: %w", err)
+	}
+	return codes, nil
+}
@@ -4,10 +4,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"backend/auth"
+	"backend/metrics"
 	"backend/middleware"
 	"backend/models"
 	"backend/utils"
@@ -40,7 +43,7 @@ func Register(w http.ResponseWriter, r *http.Request) {
 			utils.JSONError(w, "Email already exists", http.StatusConflict)
 			return
 		}
-		log.Printf("❌ Error checking if user exists: %v", err)
+		slog.ErrorContext(r.Context(), "error checking if user exists", "error", err)
 		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -58,7 +61,7 @@ func Register(w http.ResponseWriter, r *http.Request) {
 
 	// Log ako je pokusaj da se setuje druga uloga
 	if req.Role != "" {
-		log.Printf("📝 Received role from request: '%s' (will use 'user' instead)", req.Role)
+		slog.WarnContext(r.Context(), "ignoring role from registration request", "requested_role", req.Role)
 	}
 
 	// Verifikacija tipa kolone i default vrednositi u bazi za kolonu role/uloga
@@ -67,12 +70,11 @@ func Register(w http.ResponseWriter, r *http.Request) {
 		"SELECT DATA_TYPE, COLUMN_DEFAULT FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'role'",
 	).Scan(&roleType, &roleDefault)
 	if err == nil {
-		log.Printf("🔍 Role column type: %s, default: %s", roleType, roleDefault)
+		slog.DebugContext(r.Context(), "role column", "type", roleType, "default", roleDefault)
 	}
 
 	// Log informacija o korsniku koji se registruje
-	log.Printf("📝 Inserting user: name='%s', email='%s', goal='%s', role='%s', height=%v, weight=%v",
-		req.Name, req.Email, req.Goal, role, req.Height, req.Weight)
+	slog.DebugContext(r.Context(), "inserting user", "goal", req.Goal, "role", role)
 
 	// Insertovanje korisnika u bazu
 	var result sql.Result
@@ -83,7 +85,7 @@ func Register(w http.ResponseWriter, r *http.Request) {
 			req.Name, req.Email, hashedPassword, req.Goal, role, req.Height, req.Weight,
 		)
 		if err != nil {
-			log.Printf("⚠️  Insert with height/weight failed: %v, trying without...", err)
+			slog.WarnContext(r.Context(), "insert with height/weight failed, retrying without", "error", err)
 			// Fallnack na insertovanje bez visine i tezine
 			result, err = utils.DB.Exec(
 				"INSERT INTO users (name, email, password, goal, role) VALUES (?, ?, ?, ?, ?)",
@@ -98,7 +100,7 @@ func Register(w http.ResponseWriter, r *http.Request) {
 		)
 		if err != nil {
 			// Ako insert sa ulogom ne uspe pokusaj bez uloge
-			log.Printf("⚠️  Insert with role failed: %v, trying without role...", err)
+			slog.WarnContext(r.Context(), "insert with role failed, retrying without", "error", err)
 			result, err = utils.DB.Exec(
 				"INSERT INTO users (name, email, password, goal) VALUES (?, ?, ?, ?)",
 				req.Name, req.Email, hashedPassword, req.Goal,
@@ -107,12 +109,13 @@ func Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		log.Printf("❌ Error creating user: %v", err)
+		slog.ErrorContext(r.Context(), "error creating user", "error", err)
 		utils.JSONError(w, fmt.Sprintf("Failed to create user: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	userID, _ := result.LastInsertId()
+	metrics.Registrations.Inc()
 
 	// Generisi token
 	token, err := auth.GenerateToken(int(userID), req.Email)
@@ -153,14 +156,24 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Ograničenje po nalogu važi i kada napadač menja IP adrese
+	if ok, wait := settings.loginLimiter.Allow("account:" + strings.ToLower(strings.TrimSpace(req.Email))); !ok {
+		metrics.Logins.Inc("rate_limited")
+		middleware.TooManyRequests(w, r, "login", wait)
+		return
+	}
+
 	// Fetchovanje korisnika iz baze
 	var user models.User
 	var password sql.NullString
 	var height, weight sql.NullFloat64
+	var maxHeartRate sql.NullInt64
+	var lockedUntil sql.NullTime
+	var totpEnabled bool
 	err := utils.DB.QueryRow(
-		"SELECT id, name, email, password, goal, role, height, weight FROM users WHERE email = ?",
+		"SELECT id, name, email, password, goal, role, height, weight, max_heart_rate, locked_until, totp_enabled FROM users WHERE email = ?",
 		req.Email,
-	).Scan(&user.ID, &user.Name, &user.Email, &password, &user.Goal, &user.Role, &height, &weight)
+	).Scan(&user.ID, &user.Name, &user.Email, &password, &user.Goal, &user.Role, &height, &weight, &maxHeartRate, &lockedUntil, &totpEnabled)
 
 	// Convertovanjee sql.NullFloat64 u *float64
 	if height.Valid {
@@ -169,31 +182,83 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	if weight.Valid {
 		user.Weight = &weight.Float64
 	}
+	if maxHeartRate.Valid {
+		value := int(maxHeartRate.Int64)
+		user.MaxHeartRate = &value
+	}
 
 	if password.Valid && password.String != "" {
 		user.Password = password.String
 	} else {
-		log.Printf("⚠️  User %s has NULL or empty password", req.Email)
+		slog.WarnContext(r.Context(), "login for account without password or unknown email")
+		metrics.Logins.Inc("failure")
 		utils.JSONError(w, "Invalid email or password", http.StatusUnauthorized)
 		return
 	}
 
 	if err == sql.ErrNoRows {
+		metrics.Logins.Inc("failure")
 		utils.JSONError(w, "Invalid email or password", http.StatusUnauthorized)
 		return
 	}
 	if err != nil {
-		log.Printf("❌ Error fetching user: %v", err)
+		slog.ErrorContext(r.Context(), "error fetching user", "error", err)
 		utils.JSONError(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	// Zaključan nalog se ne otključava ni ispravnom lozinkom dok zaključavanje ne istekne.
+	// Odgovor je isti kao za pogrešnu lozinku ili nepostojeći email, da zaključavanje ne bi
+	// otkrilo koji su email-ovi registrovani; Retry-After šalju samo ograničenja po IP-u i nalogu.
+	if lockedUntil.Valid && time.Now().Before(lockedUntil.Time) {
+		metrics.Logins.Inc("locked")
+		slog.WarnContext(r.Context(), "login attempt on locked account", "locked_user_id", user.ID)
+		utils.JSONError(w, "Invalid email or password", http.StatusUnauthorized)
+		return
+	}
+
 	// Proveri lozinku/sifru
 	if !auth.CheckPassword(req.Password, user.Password) {
+		metrics.Logins.Inc("failure")
+		until, err := recordFailedLogin(user.ID)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "error recording failed login", "error", err)
+		} else if !until.IsZero() {
+			slog.WarnContext(r.Context(), "account locked after failed logins", "locked_user_id", user.ID, "locked_until", until)
+		}
 		utils.JSONError(w, "Invalid email or password", http.StatusUnauthorized)
 		return
 	}
 
+	// Sa uključenim 2FA lozinka daje samo challenge token; prijava se završava na /api/login/2fa.
+	// Brojač neuspelih prijava se tada briše tek posle drugog faktora, da ponovna prijava
+	// ispravnom lozinkom ne bi poništila neuspele pokušaje pogađanja koda.
+	if totpEnabled {
+		challenge, challengeID, err := auth.GenerateChallengeToken(user.ID, user.Email)
+		if err != nil {
+			utils.JSONError(w, "Failed to generate token", http.StatusInternalServerError)
+			return
+		}
+		// Važi samo poslednji izdati challenge token
+		if _, err := utils.DB.Exec("UPDATE users SET totp_challenge_id = ? WHERE id = ?", challengeID, user.ID); err != nil {
+			slog.ErrorContext(r.Context(), "error saving two-factor challenge", "error", err)
+			utils.JSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		metrics.Logins.Inc("two_factor_required")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.TwoFactorChallenge{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresIn:         int(auth.ChallengeTokenTTL.Seconds()),
+		})
+		return
+	}
+
+	if err := clearFailedLogins(user.ID); err != nil {
+		slog.ErrorContext(r.Context(), "error clearing failed logins", "error", err)
+	}
+
 	// Generisi token
 	token, err := auth.GenerateToken(user.ID, user.Email)
 	if err != nil {
@@ -203,6 +268,7 @@ func Login(w http.ResponseWriter, r *http.Request) {
 
 	// ne salji sifru u response
 	user.Password = ""
+	metrics.Logins.Inc("success")
 
 	response := models.LoginResponse{
 		User:  &user,
@@ -226,12 +292,89 @@ func GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, err := loadProfile(userID)
+	if err == sql.ErrNoRows {
+		utils.JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// UpdateProfile menja ime, visinu, težinu, maksimalni puls i nedeljni cilj treninga korisnika
+func UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.JSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	if userID == 0 {
+		utils.JSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validacija opcionih polja
+	if req.Name != nil && *req.Name == "" {
+		utils.JSONError(w, "Name must not be empty", http.StatusBadRequest)
+		return
+	}
+	if (req.Height != nil && *req.Height <= 0) || (req.Weight != nil && *req.Weight <= 0) {
+		utils.JSONError(w, "Height and weight must be positive", http.StatusBadRequest)
+		return
+	}
+	if req.MaxHeartRate != nil && !validBPM(*req.MaxHeartRate) {
+		utils.JSONError(w, "max_heart_rate must be between 20 and 250 bpm", http.StatusBadRequest)
+		return
+	}
+	if req.WeeklyWorkoutTarget != nil && (*req.WeeklyWorkoutTarget < 1 || *req.WeeklyWorkoutTarget > maxWeeklyWorkoutTarget) {
+		utils.JSONError(w, fmt.Sprintf("weekly_workout_target must be between 1 and %d", maxWeeklyWorkoutTarget), http.StatusBadRequest)
+		return
+	}
+
+	// COALESCE zadržava postojeću vrednost za polja koja nisu poslata
+	_, err := utils.DB.Exec(
+		"UPDATE users SET name = COALESCE(?, name), height = COALESCE(?, height), weight = COALESCE(?, weight), max_heart_rate = COALESCE(?, max_heart_rate), weekly_workout_target = COALESCE(?, weekly_workout_target) WHERE id = ?",
+		req.Name, req.Height, req.Weight, req.MaxHeartRate, req.WeeklyWorkoutTarget, userID,
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error updating profile", "error", err)
+		utils.JSONError(w, fmt.Sprintf("Failed to update profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	user, err := loadProfile(userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error fetching updated profile", "error", err)
+		utils.JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// loadProfile učitava korisnika bez lozinke
+func loadProfile(userID int) (models.User, error) {
 	var user models.User
 	var height, weight sql.NullFloat64
+	var maxHeartRate, weeklyTarget sql.NullInt64
+	var deletionScheduledFor sql.NullTime
 	err := utils.DB.QueryRow(
-		"SELECT id, name, email, goal, role, height, weight FROM users WHERE id = ?",
+		"SELECT id, name, email, goal, role, height, weight, max_heart_rate, weekly_workout_target, deletion_scheduled_for, created_at, updated_at FROM users WHERE id = ?",
 		userID,
-	).Scan(&user.ID, &user.Name, &user.Email, &user.Goal, &user.Role, &height, &weight)
+	).Scan(&user.ID, &user.Name, &user.Email, &user.Goal, &user.Role, &height, &weight, &maxHeartRate, &weeklyTarget, &deletionScheduledFor, &user.CreatedAt, &user.UpdatedAt)
 
 	// pretvara sql.NullFloat64 u *float64
 	if height.Valid {
@@ -240,18 +383,18 @@ func GetProfile(w http.ResponseWriter, r *http.Request) {
 	if weight.Valid {
 		user.Weight = &weight.Float64
 	}
-
-	if err == sql.ErrNoRows {
-		utils.JSONError(w, "User not found", http.StatusNotFound)
-		return
+	if maxHeartRate.Valid {
+		value := int(maxHeartRate.Int64)
+		user.MaxHeartRate = &value
 	}
-	if err != nil {
-		utils.JSONError(w, "Database error", http.StatusInternalServerError)
-		return
+	if weeklyTarget.Valid {
+		value := int(weeklyTarget.Int64)
+		user.WeeklyWorkoutTarget = &value
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	if deletionScheduledFor.Valid {
+		user.DeletionScheduledFor = &deletionScheduledFor.Time
+	}
+	return user, err
 }
 
 // Logout hendluje odjavu korisnika
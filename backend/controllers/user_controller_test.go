@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/auth"
+)
+
+// loginColumns su kolone koje Login čita za nalog
+var loginColumns = []string{"id", "name", "email", "password", "goal", "role", "height", "weight", "max_heart_rate", "locked_until", "totp_enabled"}
+
+// TestLoginFailureResponsesMatch proverava da nepostojeći email, pogrešna lozinka i
+// zaključan nalog daju isti odgovor, pa se po odgovoru ne vidi koji nalozi postoje
+func TestLoginFailureResponsesMatch(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	account := func(lockedUntil interface{}) []driver.Value {
+		return []driver.Value{int64(1), "Ana", "ana@example.com", hash, "lose_weight", "user", nil, nil, nil, lockedUntil, false}
+	}
+
+	tests := []struct {
+		name     string
+		row      []driver.Value
+		password string
+	}{
+		{"unknown email", nil, "correct horse"},
+		{"wrong password", account(nil), "wrong"},
+		{"locked account with correct password", account(time.Now().Add(time.Hour)), "correct horse"},
+	}
+
+	var first string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			if tt.row != nil {
+				db.on("FROM users WHERE email = ?", loginColumns, tt.row)
+			}
+
+			w := httptest.NewRecorder()
+			body := `{"email":"ana@example.com","password":"` + tt.password + `"}`
+			Login(w, httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(body)))
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", w.Code)
+			}
+			if retry := w.Header().Get("Retry-After"); retry != "" {
+				t.Fatalf("Retry-After = %q, want none", retry)
+			}
+			if first == "" {
+				first = w.Body.String()
+			} else if w.Body.String() != first {
+				t.Fatalf("body = %s, want the same body as for an unknown email: %s", w.Body.String(), first)
+			}
+			if tt.name == "locked account with correct password" {
+				if _, ok := db.executed("failed_login_count = 0"); ok {
+					t.Fatal("locked account login cleared the failure counter")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+// Package docs sadrži OpenAPI specifikaciju API-ja, ugrađenu u binarni fajl servera
+package docs
+
+import "embed"
+
+// OpenAPIFile je ime specifikacije unutar FS
+const OpenAPIFile = "openapi.yaml"
+
+// FS sadrži OpenAPI specifikaciju koju servira /openapi.yaml ruta
+//
+//go:embed openapi.yaml
+var FS embed.FS
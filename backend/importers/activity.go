@@ -0,0 +1,188 @@
+package importers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"backend/models"
+)
+
+// Tekstualni formati fajlova koje importer podržava (FIT je u fit.go)
+const (
+	SourceGPX = "gpx"
+	SourceTCX = "tcx"
+)
+
+// elevationNoiseMeters je prag ispod kog se promene visine smatraju šumom GPS-a
+const elevationNoiseMeters = 2.0
+
+// ErrNoTrackData se vraća kada fajl ne sadrži nijednu tačku sa vremenom
+var ErrNoTrackData = errors.New("file contains no timed track points")
+
+// Activity je zajednički rezultat parsiranja fajla sa uređaja, nezavisno od formata
+type Activity struct {
+	Source          string
+	Name            string
+	ActivityType    string // kod iz kataloga aktivnosti, prazno ako sport nije prepoznat
+	StartTime       time.Time
+	DurationSeconds float64
+	DistanceMeters  float64
+	ElevationGainM  float64
+	Calories        *float64 // kalorije zabeležene na uređaju, ako postoje
+	AvgHeartRate    *int
+	MaxHeartRate    *int
+	HeartRate       []models.HeartRateSample
+}
+
+// Fingerprint vraća stabilan otisak aktivnosti koji se koristi za prepoznavanje
+// ponovnog uvoza istog fajla. Formati različito računaju trajanje i distancu (GPX iz
+// tačaka trase, TCX iz krugova, FIT iz sesije), pa ista aktivnost izvezena u drugom
+// formatu obično ima drugačiji otisak i ne prepoznaje se kao duplikat.
+func (a *Activity) Fingerprint() string {
+	key := fmt.Sprintf("%d|%d|%d",
+		a.StartTime.UTC().Unix(),
+		int64(math.Round(a.DurationSeconds)),
+		int64(math.Round(a.DistanceMeters/10)),
+	)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// trackPoint je jedna tačka trase, zajednička za GPX i TCX
+type trackPoint struct {
+	Time      time.Time
+	Lat, Lon  float64
+	HasPos    bool
+	Elevation *float64
+	Distance  *float64 // kumulativna distanca sa uređaja (TCX)
+	HeartRate int
+}
+
+// summarizeTrack popunjava distancu, uspon i puls aktivnosti iz tačaka trase
+func summarizeTrack(activity *Activity, points []trackPoint) {
+	var distance float64
+	var lastPos *trackPoint
+	var deviceDistance float64
+	for i := range points {
+		point := &points[i]
+		if point.HasPos {
+			if lastPos != nil {
+				distance += haversineMeters(lastPos.Lat, lastPos.Lon, point.Lat, point.Lon)
+			}
+			lastPos = point
+		}
+		if point.Distance != nil && *point.Distance > deviceDistance {
+			deviceDistance = *point.Distance
+		}
+	}
+	// Distanca sa uređaja je preciznija od one izračunate iz GPS tačaka
+	if deviceDistance > 0 {
+		distance = deviceDistance
+	}
+	if activity.DistanceMeters == 0 {
+		activity.DistanceMeters = distance
+	}
+
+	// Uspon zabeležen na uređaju (FIT sesija) ima prednost nad izračunatim
+	if activity.ElevationGainM == 0 {
+		activity.ElevationGainM = elevationGain(points)
+	}
+
+	start := activity.StartTime
+	for _, point := range points {
+		if point.HeartRate <= 0 {
+			continue
+		}
+		offset := int(point.Time.Sub(start).Seconds())
+		if offset < 0 {
+			continue
+		}
+		activity.HeartRate = append(activity.HeartRate, models.HeartRateSample{OffsetSeconds: offset, BPM: point.HeartRate})
+	}
+
+	if len(activity.HeartRate) > 0 && activity.AvgHeartRate == nil {
+		total, max := 0, 0
+		for _, sample := range activity.HeartRate {
+			total += sample.BPM
+			if sample.BPM > max {
+				max = sample.BPM
+			}
+		}
+		avg := int(math.Round(float64(total) / float64(len(activity.HeartRate))))
+		activity.AvgHeartRate = &avg
+		activity.MaxHeartRate = &max
+	}
+}
+
+// elevationGain sabira uspone, uz histerezis da GPS šum ne bi naduvao rezultat
+func elevationGain(points []trackPoint) float64 {
+	var gain float64
+	var reference *float64
+	for _, point := range points {
+		if point.Elevation == nil {
+			continue
+		}
+		elevation := *point.Elevation
+		if reference == nil {
+			reference = &elevation
+			continue
+		}
+		delta := elevation - *reference
+		if delta >= elevationNoiseMeters {
+			gain += delta
+			reference = &elevation
+		} else if delta <= -elevationNoiseMeters {
+			reference = &elevation
+		}
+	}
+	return math.Round(gain*10) / 10
+}
+
+// haversineMeters računa rastojanje između dve GPS koordinate u metrima
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// activityTypeForSport mapira naziv sporta iz fajla na kod iz kataloga aktivnosti
+func activityTypeForSport(sport string) string {
+	switch normalizeSport(sport) {
+	case "running", "run", "9", "trail_running", "treadmill_running":
+		return "running"
+	case "biking", "cycling", "ride", "1", "road_biking", "mountain_biking":
+		return "cycling"
+	case "walking", "walk", "10":
+		return "walking"
+	case "hiking", "hike", "4":
+		return "hiking"
+	case "swimming", "swim", "lap_swimming", "open_water_swimming", "5":
+		return "swimming"
+	case "rowing", "row":
+		return "rowing"
+	}
+	return ""
+}
+
+func normalizeSport(sport string) string {
+	out := make([]rune, 0, len(sport))
+	for _, r := range sport {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			out = append(out, r+('a'-'A'))
+		case r == ' ' || r == '-':
+			out = append(out, '_')
+		default:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,94 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSVReader čita CSV fajl i vraća redove kao mapu polje -> vrednost,
+// prema mapiranju polja na nazive kolona iz zaglavlja
+type CSVReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// NewCSVReader čita zaglavlje i razrešava mapiranje. Polja koja nisu mapirana
+// traže se u zaglavlju po istom nazivu (bez obzira na velika/mala slova), a zatim
+// po alternativnom nazivu iz aliases. Vraća grešku ako mapiranje pokazuje na
+// kolonu koja ne postoji.
+func NewCSVReader(r io.Reader, fields []string, aliases, mapping map[string]string) (*CSVReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		// Excel dodaje UTF-8 BOM na početak fajla
+		name = strings.TrimPrefix(name, "\uFEFF")
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	known := make(map[string]bool, len(fields))
+	columns := make(map[string]int)
+	for _, field := range fields {
+		known[field] = true
+		column, mapped := mapping[field]
+		if !mapped {
+			column = field
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(column))]
+		if !ok && !mapped && aliases[field] != "" {
+			i, ok = index[aliases[field]]
+		}
+		if ok {
+			columns[field] = i
+		} else if mapped {
+			return nil, fmt.Errorf("mapped column %q for field %q not found in CSV header", column, field)
+		}
+	}
+	for field := range mapping {
+		if !known[field] {
+			return nil, fmt.Errorf("unknown field %q in column mapping", field)
+		}
+	}
+
+	return &CSVReader{reader: reader, columns: columns}, nil
+}
+
+// Next vraća sledeći red (broj linije u fajlu i vrednosti polja) ili io.EOF.
+// Prazni redovi se preskaču.
+func (c *CSVReader) Next() (int, map[string]string, error) {
+	for {
+		record, err := c.reader.Read()
+		if err != nil {
+			return 0, nil, err
+		}
+		line, _ := c.reader.FieldPos(0)
+
+		values := make(map[string]string, len(c.columns))
+		empty := true
+		for field, i := range c.columns {
+			if i < len(record) {
+				value := strings.TrimSpace(record[i])
+				values[field] = value
+				if value != "" {
+					empty = false
+				}
+			}
+		}
+		if !empty {
+			return line, values, nil
+		}
+	}
+}
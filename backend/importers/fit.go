@@ -0,0 +1,510 @@
+package importers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SourceFIT označava trening uvezen iz binarnog FIT fajla (Garmin, Wahoo, Coros...)
+const SourceFIT = "fit"
+
+// Globalni brojevi FIT poruka koje dekoder mapira
+const (
+	fitMesgFileID  = 0
+	fitMesgSession = 18
+	fitMesgLap     = 19
+	fitMesgRecord  = 20
+)
+
+// fitFieldTimestamp je broj polja timestamp, zajednički za sve poruke
+const fitFieldTimestamp = 253
+
+// fitFileTypeActivity je vrednost file_id.type za fajlove sa aktivnošću
+const fitFileTypeActivity = 4
+
+// fitEpoch je početak FIT vremena (1989-12-31 00:00:00 UTC)
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+var (
+	// ErrInvalidFIT se vraća kada fajl nema ispravno FIT zaglavlje ili kontrolnu sumu
+	ErrInvalidFIT = errors.New("invalid FIT file")
+	// ErrNoActivityMessages se vraća kada FIT fajl nema session, lap ni record poruke
+	ErrNoActivityMessages = errors.New("FIT file contains no session, lap or record messages")
+)
+
+// UnsupportedFileTypeError se vraća za FIT fajlove koji nisu aktivnosti
+// (npr. podešavanja uređaja, planirani treninzi ili rute)
+type UnsupportedFileTypeError struct {
+	Type int
+}
+
+func (e *UnsupportedFileTypeError) Error() string {
+	name := fitFileTypeNames[e.Type]
+	if name == "" {
+		name = "unknown"
+	}
+	return fmt.Sprintf("unsupported FIT file type %d (%s): only activity files can be imported", e.Type, name)
+}
+
+var fitFileTypeNames = map[int]string{
+	1: "device", 2: "settings", 3: "sport", 4: "activity", 5: "workout", 6: "course",
+	7: "schedules", 9: "weight", 10: "totals", 11: "goals", 14: "blood_pressure",
+	15: "monitoring_a", 20: "activity_summary", 28: "monitoring_daily", 32: "monitoring_b", 34: "segment",
+}
+
+var fitMessageNames = map[uint16]string{
+	0: "file_id", 2: "device_settings", 3: "user_profile", 12: "sport", 18: "session", 19: "lap",
+	20: "record", 21: "event", 23: "device_info", 26: "workout", 27: "workout_step", 31: "course",
+	34: "activity", 49: "file_creator", 55: "monitoring", 78: "hrv", 206: "field_description", 207: "developer_data_id",
+}
+
+func fitMessageName(global uint16) string {
+	if name, ok := fitMessageNames[global]; ok {
+		return name
+	}
+	return fmt.Sprintf("#%d", global)
+}
+
+// fitSportActivity mapira FIT sport enum na kod iz kataloga aktivnosti
+var fitSportActivity = map[int]string{
+	1: "running", 2: "cycling", 5: "swimming", 11: "walking", 15: "rowing", 17: "hiking",
+}
+
+// fitBaseType opisuje osnovni tip polja: veličinu u bajtovima i nevažeću vrednost
+type fitBaseType struct {
+	size    int
+	signed  bool
+	float   bool
+	invalid uint64
+}
+
+var fitBaseTypes = map[byte]fitBaseType{
+	0x00: {1, false, false, 0xFF},               // enum
+	0x01: {1, true, false, 0x7F},                // sint8
+	0x02: {1, false, false, 0xFF},               // uint8
+	0x83: {2, true, false, 0x7FFF},              // sint16
+	0x84: {2, false, false, 0xFFFF},             // uint16
+	0x85: {4, true, false, 0x7FFFFFFF},          // sint32
+	0x86: {4, false, false, 0xFFFFFFFF},         // uint32
+	0x07: {1, false, false, 0x00},               // string
+	0x88: {4, false, true, 0xFFFFFFFF},          // float32
+	0x89: {8, false, true, 0xFFFFFFFFFFFFFFFF},  // float64
+	0x0A: {1, false, false, 0x00},               // uint8z
+	0x8B: {2, false, false, 0x0000},             // uint16z
+	0x8C: {4, false, false, 0x00000000},         // uint32z
+	0x0D: {1, false, false, 0xFF},               // byte
+	0x8E: {8, true, false, 0x7FFFFFFFFFFFFFFF},  // sint64
+	0x8F: {8, false, false, 0xFFFFFFFFFFFFFFFF}, // uint64
+	0x90: {8, false, false, 0x0000000000000000}, // uint64z
+}
+
+type fitFieldDef struct {
+	num      byte
+	size     int
+	baseType byte
+}
+
+type fitDefinition struct {
+	global    uint16
+	order     binary.ByteOrder
+	fields    []fitFieldDef
+	devFields int // ukupna veličina polja developer podataka, koja se preskaču
+}
+
+// FITMessage je jedna dekodirana poruka sa numeričkim poljima (već bez nevažećih vrednosti)
+type FITMessage struct {
+	Global uint16
+	Fields map[byte]float64
+}
+
+// Value vraća vrednost polja i da li je polje prisutno
+func (m FITMessage) Value(field byte) (float64, bool) {
+	v, ok := m.Fields[field]
+	return v, ok
+}
+
+// FITFile sadrži poruke iz FIT fajla koje su relevantne za treninge
+type FITFile struct {
+	FileType int
+	Sessions []FITMessage
+	Laps     []FITMessage
+	Records  []FITMessage
+	// Skipped broji ostale poruke po imenu, radi jasnijih grešaka
+	Skipped map[string]int
+}
+
+// DecodeFIT dekodira binarni FIT fajl: proverava zaglavlje i CRC, čita definicione
+// i data poruke (uključujući compressed timestamp zaglavlja) i izdvaja session,
+// lap i record poruke.
+func DecodeFIT(r io.Reader) (*FITFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FIT file: %w", err)
+	}
+	if len(data) < 12 {
+		return nil, fmt.Errorf("%w: file too short", ErrInvalidFIT)
+	}
+
+	headerSize := int(data[0])
+	if (headerSize != 12 && headerSize != 14) || len(data) < headerSize || string(data[8:12]) != ".FIT" {
+		return nil, fmt.Errorf("%w: missing .FIT header", ErrInvalidFIT)
+	}
+	dataSize := int(binary.LittleEndian.Uint32(data[4:8]))
+	end := headerSize + dataSize
+	if end+2 > len(data) {
+		return nil, fmt.Errorf("%w: file is truncated (expected %d bytes, got %d)", ErrInvalidFIT, end+2, len(data))
+	}
+	// CRC preko zaglavlja, podataka i same kontrolne sume mora biti 0
+	if fitCRC(data[:end+2]) != 0 {
+		return nil, fmt.Errorf("%w: CRC mismatch", ErrInvalidFIT)
+	}
+
+	decoder := &fitDecoder{data: data[:end], pos: headerSize}
+	file := &FITFile{FileType: -1, Skipped: make(map[string]int)}
+	for decoder.pos < end {
+		message, ok, err := decoder.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		switch message.Global {
+		case fitMesgFileID:
+			if fileType, present := message.Value(0); present {
+				file.FileType = int(fileType)
+			}
+		case fitMesgSession:
+			file.Sessions = append(file.Sessions, message)
+		case fitMesgLap:
+			file.Laps = append(file.Laps, message)
+		case fitMesgRecord:
+			file.Records = append(file.Records, message)
+		default:
+			file.Skipped[fitMessageName(message.Global)]++
+		}
+	}
+	return file, nil
+}
+
+type fitDecoder struct {
+	data          []byte
+	pos           int
+	definitions   [16]*fitDefinition
+	lastTimestamp uint32
+}
+
+func (d *fitDecoder) read(n int) ([]byte, error) {
+	if d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("%w: unexpected end of data at byte %d", ErrInvalidFIT, d.pos)
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// next čita sledeći zapis. Za definicione poruke vraća ok=false.
+func (d *fitDecoder) next() (FITMessage, bool, error) {
+	headerBytes, err := d.read(1)
+	if err != nil {
+		return FITMessage{}, false, err
+	}
+	header := headerBytes[0]
+
+	// Compressed timestamp zaglavlje: uvek data poruka, lokalni tip u bitovima 5-6
+	if header&0x80 != 0 {
+		local := (header >> 5) & 0x03
+		offset := uint32(header & 0x1F)
+		timestamp := (d.lastTimestamp &^ 0x1F) | offset
+		if offset < d.lastTimestamp&0x1F {
+			timestamp += 0x20
+		}
+		message, err := d.readData(local)
+		if err != nil {
+			return FITMessage{}, false, err
+		}
+		d.lastTimestamp = timestamp
+		message.Fields[fitFieldTimestamp] = float64(timestamp)
+		return message, true, nil
+	}
+
+	local := header & 0x0F
+	if header&0x40 != 0 {
+		return FITMessage{}, false, d.readDefinition(local, header&0x20 != 0)
+	}
+	message, err := d.readData(local)
+	return message, err == nil, err
+}
+
+func (d *fitDecoder) readDefinition(local byte, hasDevFields bool) error {
+	fixed, err := d.read(5)
+	if err != nil {
+		return err
+	}
+	def := &fitDefinition{order: binary.LittleEndian}
+	if fixed[1] == 1 {
+		def.order = binary.BigEndian
+	}
+	def.global = def.order.Uint16(fixed[2:4])
+
+	count := int(fixed[4])
+	raw, err := d.read(count * 3)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		// Polja nepoznatog osnovnog tipa (novije verzije SDK-a) se preskaču po deklarisanoj veličini
+		def.fields = append(def.fields, fitFieldDef{num: raw[i*3], size: int(raw[i*3+1]), baseType: raw[i*3+2]})
+	}
+
+	if hasDevFields {
+		countByte, err := d.read(1)
+		if err != nil {
+			return err
+		}
+		devRaw, err := d.read(int(countByte[0]) * 3)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < int(countByte[0]); i++ {
+			def.devFields += int(devRaw[i*3+1])
+		}
+	}
+
+	d.definitions[local] = def
+	return nil
+}
+
+func (d *fitDecoder) readData(local byte) (FITMessage, error) {
+	def := d.definitions[local]
+	if def == nil {
+		return FITMessage{}, fmt.Errorf("%w: data message for undefined local type %d at byte %d", ErrInvalidFIT, local, d.pos)
+	}
+
+	message := FITMessage{Global: def.global, Fields: make(map[byte]float64, len(def.fields))}
+	for _, field := range def.fields {
+		raw, err := d.read(field.size)
+		if err != nil {
+			return FITMessage{}, err
+		}
+		if value, ok := decodeFITValue(raw, field, def.order); ok {
+			message.Fields[field.num] = value
+		}
+	}
+	if _, err := d.read(def.devFields); err != nil {
+		return FITMessage{}, err
+	}
+
+	if timestamp, ok := message.Fields[fitFieldTimestamp]; ok {
+		d.lastTimestamp = uint32(timestamp)
+	}
+	return message, nil
+}
+
+// decodeFITValue dekodira skalarno numeričko polje. Nizovi, stringovi, polja
+// nepoznatog osnovnog tipa i nevažeće vrednosti se preskaču (ok=false).
+func decodeFITValue(raw []byte, field fitFieldDef, order binary.ByteOrder) (float64, bool) {
+	baseType, known := fitBaseTypes[field.baseType]
+	if !known || field.size != baseType.size || field.baseType == 0x07 {
+		return 0, false
+	}
+
+	var bits uint64
+	switch baseType.size {
+	case 1:
+		bits = uint64(raw[0])
+	case 2:
+		bits = uint64(order.Uint16(raw))
+	case 4:
+		bits = uint64(order.Uint32(raw))
+	case 8:
+		bits = order.Uint64(raw)
+	}
+	if bits == baseType.invalid {
+		return 0, false
+	}
+
+	switch {
+	case baseType.float && baseType.size == 4:
+		return float64(math.Float32frombits(uint32(bits))), true
+	case baseType.float:
+		return math.Float64frombits(bits), true
+	case baseType.signed:
+		shift := 64 - uint(baseType.size*8)
+		return float64(int64(bits<<shift) >> shift), true
+	}
+	return float64(bits), true
+}
+
+var fitCRCTable = [16]uint16{
+	0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+	0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+}
+
+// fitCRC računa CRC-16 po FIT specifikaciji
+func fitCRC(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		tmp := fitCRCTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ fitCRCTable[b&0xF]
+		tmp = fitCRCTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ fitCRCTable[(b>>4)&0xF]
+	}
+	return crc
+}
+
+// Brojevi polja session/lap poruka (razlikuju se samo za puls i uspon)
+type fitSummaryFields struct {
+	avgHeartRate, maxHeartRate, totalAscent, sport byte
+}
+
+var (
+	fitSessionFields = fitSummaryFields{avgHeartRate: 16, maxHeartRate: 17, totalAscent: 22, sport: 5}
+	fitLapFields     = fitSummaryFields{avgHeartRate: 15, maxHeartRate: 16, totalAscent: 21, sport: 25}
+)
+
+// ParseFIT dekodira FIT fajl i mapira session (ili lap) poruke na aktivnost,
+// a record poruke na trasu i uzorke pulsa
+func ParseFIT(r io.Reader) (*Activity, error) {
+	file, err := DecodeFIT(r)
+	if err != nil {
+		return nil, err
+	}
+	if file.FileType != -1 && file.FileType != fitFileTypeActivity {
+		return nil, &UnsupportedFileTypeError{Type: file.FileType}
+	}
+	if len(file.Sessions) == 0 && len(file.Laps) == 0 && len(file.Records) == 0 {
+		if len(file.Skipped) == 0 {
+			return nil, ErrNoActivityMessages
+		}
+		names := make([]string, 0, len(file.Skipped))
+		for name := range file.Skipped {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w (found only: %s)", ErrNoActivityMessages, strings.Join(names, ", "))
+	}
+
+	activity := &Activity{Source: SourceFIT}
+
+	// Sesije su zbirni podaci uređaja; ako ih nema, koriste se krugovi
+	summaries, fields := file.Sessions, fitSessionFields
+	if len(summaries) == 0 {
+		summaries, fields = file.Laps, fitLapFields
+	}
+	applyFITSummaries(activity, summaries, fields)
+
+	points := make([]trackPoint, 0, len(file.Records))
+	for _, record := range file.Records {
+		timestamp, ok := record.Value(fitFieldTimestamp)
+		if !ok {
+			continue
+		}
+		point := trackPoint{Time: fitTime(timestamp)}
+		lat, hasLat := record.Value(0)
+		lon, hasLon := record.Value(1)
+		if hasLat && hasLon {
+			point.Lat, point.Lon, point.HasPos = semicirclesToDegrees(lat), semicirclesToDegrees(lon), true
+		}
+		if altitude, ok := record.Value(78); ok { // enhanced_altitude
+			value := altitude/5 - 500
+			point.Elevation = &value
+		} else if altitude, ok := record.Value(2); ok {
+			value := altitude/5 - 500
+			point.Elevation = &value
+		}
+		if distance, ok := record.Value(5); ok {
+			value := distance / 100
+			point.Distance = &value
+		}
+		if hr, ok := record.Value(3); ok {
+			point.HeartRate = int(hr)
+		}
+		points = append(points, point)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+
+	if len(points) > 0 {
+		if activity.StartTime.IsZero() || points[0].Time.Before(activity.StartTime) {
+			activity.StartTime = points[0].Time
+		}
+		if activity.DurationSeconds == 0 {
+			activity.DurationSeconds = points[len(points)-1].Time.Sub(points[0].Time).Seconds()
+		}
+	}
+	if activity.StartTime.IsZero() {
+		return nil, fmt.Errorf("%w: activity has no start time", ErrNoActivityMessages)
+	}
+
+	summarizeTrack(activity, points)
+	return activity, nil
+}
+
+// applyFITSummaries sabira trajanje, distancu, kalorije i uspon iz session/lap poruka
+func applyFITSummaries(activity *Activity, summaries []FITMessage, fields fitSummaryFields) {
+	var calories, ascent, weightedHR, hrSeconds float64
+	maxHR := 0
+	for _, summary := range summaries {
+		if start, ok := summary.Value(2); ok {
+			startTime := fitTime(start)
+			if activity.StartTime.IsZero() || startTime.Before(activity.StartTime) {
+				activity.StartTime = startTime
+			}
+		}
+		// total_timer_time (bez pauza), a ako ga nema total_elapsed_time; skala 1000
+		seconds, ok := summary.Value(8)
+		if !ok {
+			seconds, _ = summary.Value(7)
+		}
+		seconds /= 1000
+		activity.DurationSeconds += seconds
+
+		if distance, ok := summary.Value(9); ok {
+			activity.DistanceMeters += distance / 100
+		}
+		if kcal, ok := summary.Value(11); ok {
+			calories += kcal
+		}
+		if gain, ok := summary.Value(fields.totalAscent); ok {
+			ascent += gain
+		}
+		if hr, ok := summary.Value(fields.avgHeartRate); ok && seconds > 0 {
+			weightedHR += hr * seconds
+			hrSeconds += seconds
+		}
+		if hr, ok := summary.Value(fields.maxHeartRate); ok && int(hr) > maxHR {
+			maxHR = int(hr)
+		}
+		if sport, ok := summary.Value(fields.sport); ok && activity.ActivityType == "" {
+			activity.ActivityType = fitSportActivity[int(sport)]
+		}
+	}
+
+	if calories > 0 {
+		activity.Calories = &calories
+	}
+	activity.ElevationGainM = ascent
+	if hrSeconds > 0 {
+		avg := int(math.Round(weightedHR / hrSeconds))
+		activity.AvgHeartRate = &avg
+	}
+	if maxHR > 0 {
+		activity.MaxHeartRate = &maxHR
+	}
+}
+
+func fitTime(seconds float64) time.Time {
+	return fitEpoch.Add(time.Duration(seconds) * time.Second)
+}
+
+func semicirclesToDegrees(value float64) float64 {
+	return value * (180.0 / math.Pow(2, 31))
+}
@@ -0,0 +1,286 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// fitField je definicija polja u test fixture-u: broj, veličina i osnovni tip
+type fitField struct {
+	num, size, baseType byte
+}
+
+// fitDefinitionRecord pravi definicionu poruku za lokalni tip
+func fitDefinitionRecord(local byte, global uint16, bigEndian bool, fields []fitField, devFields []fitField) []byte {
+	header := 0x40 | local
+	if len(devFields) > 0 {
+		header |= 0x20
+	}
+	order := binary.ByteOrder(binary.LittleEndian)
+	arch := byte(0)
+	if bigEndian {
+		order, arch = binary.BigEndian, 1
+	}
+	out := []byte{header, 0, arch, 0, 0, byte(len(fields))}
+	order.PutUint16(out[3:5], global)
+	for _, field := range fields {
+		out = append(out, field.num, field.size, field.baseType)
+	}
+	if len(devFields) > 0 {
+		out = append(out, byte(len(devFields)))
+		for _, field := range devFields {
+			out = append(out, field.num, field.size, field.baseType)
+		}
+	}
+	return out
+}
+
+// fitDataRecord pravi data poruku sa normalnim zaglavljem
+func fitDataRecord(local byte, payload ...[]byte) []byte {
+	return append([]byte{local}, bytes.Join(payload, nil)...)
+}
+
+func le16(v uint16) []byte { return binary.LittleEndian.AppendUint16(nil, v) }
+func le32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+func be16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+// fitFileBytes pakuje zapise u FIT fajl sa 14-bajtnim zaglavljem i ispravnim CRC-om
+func fitFileBytes(records ...[]byte) []byte {
+	data := bytes.Join(records, nil)
+	header := []byte{14, 0x20, 0, 0, 0, 0, 0, 0, '.', 'F', 'I', 'T', 0, 0}
+	binary.LittleEndian.PutUint16(header[2:4], 2132)
+	binary.LittleEndian.PutUint32(header[4:8], uint32(len(data)))
+	binary.LittleEndian.PutUint16(header[12:14], fitCRC(header[:12]))
+	file := append(header, data...)
+	return binary.LittleEndian.AppendUint16(file, fitCRC(file))
+}
+
+// fitFileID vraća definiciju i file_id poruku sa datim tipom fajla
+func fitFileID(fileType byte) [][]byte {
+	return [][]byte{
+		fitDefinitionRecord(0, fitMesgFileID, false, []fitField{{0, 1, 0x00}}, nil),
+		fitDataRecord(0, []byte{fileType}),
+	}
+}
+
+func fitTimestamp(t time.Time) uint32 {
+	return uint32(t.Sub(fitEpoch) / time.Second)
+}
+
+func TestParseFITActivity(t *testing.T) {
+	start := time.Date(2024, 5, 4, 7, 30, 0, 0, time.UTC)
+	ts := fitTimestamp(start)
+
+	sessionFields := []fitField{
+		{253, 4, 0x86}, {2, 4, 0x86}, {7, 4, 0x86}, {8, 4, 0x86}, {9, 4, 0x86},
+		{5, 1, 0x00}, {16, 1, 0x02}, {17, 1, 0x02}, {22, 2, 0x84}, {11, 2, 0x84},
+	}
+	recordFields := []fitField{{253, 4, 0x86}, {3, 1, 0x02}, {2, 2, 0x84}, {5, 4, 0x86}}
+
+	records := fitFileID(fitFileTypeActivity)
+	records = append(records,
+		fitDefinitionRecord(1, fitMesgRecord, false, recordFields, nil),
+		// altitude: (100 m + 500) * 5, distance: 0 i 10 m (skala 100)
+		fitDataRecord(1, le32(ts), []byte{120}, le16(3000), le32(0)),
+		fitDataRecord(1, le32(ts+10), []byte{140}, le16(3010), le32(1000)),
+		fitDefinitionRecord(2, fitMesgSession, false, sessionFields, nil),
+		fitDataRecord(2,
+			le32(ts+1800), le32(ts),
+			le32(1900000), // total_elapsed_time: 1900 s
+			le32(1800000), // total_timer_time: 1800 s
+			le32(500000),  // total_distance: 5000 m
+			[]byte{1},     // sport: running
+			[]byte{150}, []byte{172},
+			le16(42), le16(380),
+		),
+	)
+
+	activity, err := ParseFIT(bytes.NewReader(fitFileBytes(records...)))
+	if err != nil {
+		t.Fatalf("ParseFIT: %v", err)
+	}
+	if !activity.StartTime.Equal(start) {
+		t.Errorf("StartTime = %v, want %v", activity.StartTime, start)
+	}
+	if activity.DurationSeconds != 1800 {
+		t.Errorf("DurationSeconds = %v, want 1800 (timer time, not elapsed)", activity.DurationSeconds)
+	}
+	if activity.DistanceMeters != 5000 {
+		t.Errorf("DistanceMeters = %v, want 5000", activity.DistanceMeters)
+	}
+	if activity.ActivityType != "running" {
+		t.Errorf("ActivityType = %q, want running", activity.ActivityType)
+	}
+	if activity.ElevationGainM != 42 {
+		t.Errorf("ElevationGainM = %v, want 42", activity.ElevationGainM)
+	}
+	if activity.Calories == nil || *activity.Calories != 380 {
+		t.Errorf("Calories = %v, want 380", activity.Calories)
+	}
+	if activity.AvgHeartRate == nil || *activity.AvgHeartRate != 150 || activity.MaxHeartRate == nil || *activity.MaxHeartRate != 172 {
+		t.Errorf("heart rate = %v/%v, want 150/172", activity.AvgHeartRate, activity.MaxHeartRate)
+	}
+	if len(activity.HeartRate) != 2 || activity.HeartRate[1].OffsetSeconds != 10 || activity.HeartRate[1].BPM != 140 {
+		t.Errorf("HeartRate = %+v, want two samples ending at offset 10 with 140 bpm", activity.HeartRate)
+	}
+}
+
+func TestDecodeFITScaledAltitude(t *testing.T) {
+	records := fitFileID(fitFileTypeActivity)
+	records = append(records,
+		fitDefinitionRecord(1, fitMesgRecord, false, []fitField{{253, 4, 0x86}, {2, 2, 0x84}}, nil),
+		fitDataRecord(1, le32(1000), le16(3000)),
+	)
+	file, err := DecodeFIT(bytes.NewReader(fitFileBytes(records...)))
+	if err != nil {
+		t.Fatalf("DecodeFIT: %v", err)
+	}
+	// Sirova vrednost se čuva; skala 5 i pomeraj 500 primenjuju se pri mapiranju
+	if raw, _ := file.Records[0].Value(2); raw/5-500 != 100 {
+		t.Errorf("altitude = %v m, want 100", raw/5-500)
+	}
+}
+
+func TestDecodeFITErrors(t *testing.T) {
+	valid := fitFileBytes(fitFileID(fitFileTypeActivity)...)
+
+	badCRC := append([]byte(nil), valid...)
+	badCRC[len(badCRC)-3] ^= 0xFF
+
+	badHeader := append([]byte(nil), valid...)
+	copy(badHeader[8:12], "FIT.")
+
+	undefinedLocal := fitFileBytes(fitDataRecord(3, []byte{1}))
+
+	truncatedMessage := fitFileBytes(
+		fitDefinitionRecord(0, fitMesgRecord, false, []fitField{{253, 4, 0x86}}, nil),
+		fitDataRecord(0, []byte{1, 2}),
+	)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"too short", valid[:8]},
+		{"truncated file", valid[:len(valid)-4]},
+		{"bad CRC", badCRC},
+		{"missing .FIT signature", badHeader},
+		{"data for undefined local type", undefinedLocal},
+		{"message longer than data", truncatedMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeFIT(bytes.NewReader(tt.data))
+			if !errors.Is(err, ErrInvalidFIT) {
+				t.Fatalf("err = %v, want ErrInvalidFIT", err)
+			}
+		})
+	}
+}
+
+func TestParseFITRejectsNonActivityFile(t *testing.T) {
+	_, err := ParseFIT(bytes.NewReader(fitFileBytes(fitFileID(5)...))) // workout
+	var typeErr *UnsupportedFileTypeError
+	if !errors.As(err, &typeErr) || typeErr.Type != 5 {
+		t.Fatalf("err = %v, want UnsupportedFileTypeError for type 5", err)
+	}
+}
+
+func TestDecodeFITCompressedTimestampRollover(t *testing.T) {
+	const base = 0x1000001E // donjih 5 bitova = 30
+	records := [][]byte{
+		fitDefinitionRecord(1, fitMesgRecord, false, []fitField{{253, 4, 0x86}, {3, 1, 0x02}}, nil),
+		fitDataRecord(1, le32(base), []byte{100}),
+		fitDefinitionRecord(2, fitMesgRecord, false, []fitField{{3, 1, 0x02}}, nil),
+		// Compressed zaglavlje: bit 7, lokalni tip 2 u bitovima 5-6, offset 31 pa 2 (prelaz preko 32)
+		{0x80 | 2<<5 | 31, 101},
+		{0x80 | 2<<5 | 2, 102},
+	}
+	file, err := DecodeFIT(bytes.NewReader(fitFileBytes(records...)))
+	if err != nil {
+		t.Fatalf("DecodeFIT: %v", err)
+	}
+	want := []float64{base, base + 1, base + 4}
+	if len(file.Records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(file.Records), len(want))
+	}
+	for i, record := range file.Records {
+		if ts, _ := record.Value(fitFieldTimestamp); ts != want[i] {
+			t.Errorf("record %d timestamp = %#x, want %#x", i, uint32(ts), uint32(want[i]))
+		}
+	}
+}
+
+func TestDecodeFITBigEndianDefinition(t *testing.T) {
+	records := [][]byte{
+		fitDefinitionRecord(0, fitMesgRecord, true, []fitField{{253, 4, 0x86}, {2, 2, 0x84}, {0, 4, 0x85}}, nil),
+		fitDataRecord(0, be32(123456), be16(2600), be32(uint32(0xFFFFFF00))), // lat = -256 (sint32)
+	}
+	file, err := DecodeFIT(bytes.NewReader(fitFileBytes(records...)))
+	if err != nil {
+		t.Fatalf("DecodeFIT: %v", err)
+	}
+	record := file.Records[0]
+	if ts, _ := record.Value(fitFieldTimestamp); ts != 123456 {
+		t.Errorf("timestamp = %v, want 123456", ts)
+	}
+	if altitude, _ := record.Value(2); altitude != 2600 {
+		t.Errorf("altitude = %v, want 2600", altitude)
+	}
+	if lat, _ := record.Value(0); lat != -256 {
+		t.Errorf("lat = %v, want -256", lat)
+	}
+}
+
+func TestDecodeFITSkipsDeveloperAndUnknownFields(t *testing.T) {
+	records := [][]byte{
+		fitDefinitionRecord(0, fitMesgRecord, false,
+			[]fitField{{253, 4, 0x86}, {99, 4, 0x91}, {3, 1, 0x02}}, // 0x91: osnovni tip koji dekoder ne poznaje
+			[]fitField{{0, 3, 0}}, // developer polje od 3 bajta
+		),
+		fitDataRecord(0, le32(500), []byte{1, 2, 3, 4}, []byte{130}, []byte{9, 9, 9}),
+		fitDataRecord(0, le32(501), []byte{1, 2, 3, 4}, []byte{0xFF}, []byte{9, 9, 9}), // 0xFF: nevažeći puls
+	}
+	file, err := DecodeFIT(bytes.NewReader(fitFileBytes(records...)))
+	if err != nil {
+		t.Fatalf("DecodeFIT: %v", err)
+	}
+	if len(file.Records) != 2 {
+		t.Fatalf("got %d records, want 2", len(file.Records))
+	}
+	if hr, ok := file.Records[0].Value(3); !ok || hr != 130 {
+		t.Errorf("heart rate = %v (present %v), want 130", hr, ok)
+	}
+	if _, ok := file.Records[0].Value(99); ok {
+		t.Error("field with unknown base type should be skipped")
+	}
+	if _, ok := file.Records[1].Value(3); ok {
+		t.Error("invalid value 0xFF should be skipped")
+	}
+	if ts, _ := file.Records[1].Value(fitFieldTimestamp); ts != 501 {
+		t.Errorf("second record timestamp = %v, want 501 (developer bytes misaligned?)", ts)
+	}
+}
+
+func TestFITCRC(t *testing.T) {
+	data := []byte("123456789")
+	// CRC-16/ARC kontrolna vrednost
+	if crc := fitCRC(data); crc != 0xBB3D {
+		t.Errorf("fitCRC = %#04x, want 0xbb3d", crc)
+	}
+	withCRC := binary.LittleEndian.AppendUint16(append([]byte(nil), data...), fitCRC(data))
+	if crc := fitCRC(withCRC); crc != 0 {
+		t.Errorf("fitCRC over data and its checksum = %#04x, want 0", crc)
+	}
+}
+
+func TestSemicirclesToDegrees(t *testing.T) {
+	if got := semicirclesToDegrees(math.Pow(2, 30)); got != 90 {
+		t.Errorf("semicirclesToDegrees(2^30) = %v, want 90", got)
+	}
+}
@@ -0,0 +1,80 @@
+package importers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+type gpxFile struct {
+	Metadata struct {
+		Name string    `xml:"name"`
+		Time time.Time `xml:"time"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Lat       float64    `xml:"lat,attr"`
+				Lon       float64    `xml:"lon,attr"`
+				Elevation *float64   `xml:"ele"`
+				Time      *time.Time `xml:"time"`
+				// Garmin TrackPointExtension (gpxtpx:hr) i varijante bez prefiksa
+				HeartRate   int `xml:"extensions>TrackPointExtension>hr"`
+				HeartRateV1 int `xml:"extensions>hr"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// ParseGPX parsira GPX fajl u aktivnost. Trajanje je proteklo vreme između
+// prve i poslednje tačke, a distanca se računa iz GPS koordinata.
+func ParseGPX(r io.Reader) (*Activity, error) {
+	var file gpxFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid GPX file: %w", err)
+	}
+
+	activity := &Activity{Source: SourceGPX, Name: file.Metadata.Name}
+	var points []trackPoint
+	for _, track := range file.Tracks {
+		if activity.Name == "" {
+			activity.Name = track.Name
+		}
+		if activity.ActivityType == "" {
+			activity.ActivityType = activityTypeForSport(track.Type)
+		}
+		for _, segment := range track.Segments {
+			for _, p := range segment.Points {
+				if p.Time == nil {
+					continue
+				}
+				hr := p.HeartRate
+				if hr == 0 {
+					hr = p.HeartRateV1
+				}
+				points = append(points, trackPoint{
+					Time:      *p.Time,
+					Lat:       p.Lat,
+					Lon:       p.Lon,
+					HasPos:    true,
+					Elevation: p.Elevation,
+					HeartRate: hr,
+				})
+			}
+		}
+	}
+
+	if len(points) == 0 {
+		return nil, ErrNoTrackData
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+
+	activity.StartTime = points[0].Time
+	activity.DurationSeconds = points[len(points)-1].Time.Sub(points[0].Time).Seconds()
+	summarizeTrack(activity, points)
+	return activity, nil
+}
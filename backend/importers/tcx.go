@@ -0,0 +1,108 @@
+package importers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string    `xml:"Sport,attr"`
+		ID    time.Time `xml:"Id"`
+		Notes string    `xml:"Notes"`
+		Laps  []struct {
+			StartTime        time.Time `xml:"StartTime,attr"`
+			TotalTimeSeconds float64   `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64   `xml:"DistanceMeters"`
+			Calories         float64   `xml:"Calories"`
+			AvgHeartRate     int       `xml:"AverageHeartRateBpm>Value"`
+			MaxHeartRate     int       `xml:"MaximumHeartRateBpm>Value"`
+			Trackpoints      []struct {
+				Time      *time.Time `xml:"Time"`
+				Latitude  *float64   `xml:"Position>LatitudeDegrees"`
+				Longitude *float64   `xml:"Position>LongitudeDegrees"`
+				Altitude  *float64   `xml:"AltitudeMeters"`
+				Distance  *float64   `xml:"DistanceMeters"`
+				HeartRate int        `xml:"HeartRateBpm>Value"`
+			} `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// ParseTCX parsira prvu aktivnost iz TCX fajla. Trajanje, distanca i kalorije
+// se sabiraju po krugovima, onako kako ih je zabeležio uređaj.
+func ParseTCX(r io.Reader) (*Activity, error) {
+	var file tcxFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid TCX file: %w", err)
+	}
+	if len(file.Activities) == 0 {
+		return nil, ErrNoTrackData
+	}
+
+	source := file.Activities[0]
+	activity := &Activity{
+		Source:       SourceTCX,
+		Name:         source.Notes,
+		ActivityType: activityTypeForSport(source.Sport),
+		StartTime:    source.ID,
+	}
+
+	var points []trackPoint
+	var calories float64
+	var weightedHR, maxHR int
+	for _, lap := range source.Laps {
+		activity.DurationSeconds += lap.TotalTimeSeconds
+		activity.DistanceMeters += lap.DistanceMeters
+		calories += lap.Calories
+		weightedHR += lap.AvgHeartRate * int(lap.TotalTimeSeconds)
+		if lap.MaxHeartRate > maxHR {
+			maxHR = lap.MaxHeartRate
+		}
+		if activity.StartTime.IsZero() || (!lap.StartTime.IsZero() && lap.StartTime.Before(activity.StartTime)) {
+			activity.StartTime = lap.StartTime
+		}
+
+		for _, tp := range lap.Trackpoints {
+			if tp.Time == nil {
+				continue
+			}
+			point := trackPoint{Time: *tp.Time, Elevation: tp.Altitude, Distance: tp.Distance, HeartRate: tp.HeartRate}
+			if tp.Latitude != nil && tp.Longitude != nil {
+				point.Lat, point.Lon, point.HasPos = *tp.Latitude, *tp.Longitude, true
+			}
+			points = append(points, point)
+		}
+	}
+
+	if len(points) == 0 && activity.DurationSeconds == 0 {
+		return nil, ErrNoTrackData
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	if len(points) > 0 {
+		if activity.StartTime.IsZero() {
+			activity.StartTime = points[0].Time
+		}
+		if activity.DurationSeconds == 0 {
+			activity.DurationSeconds = points[len(points)-1].Time.Sub(points[0].Time).Seconds()
+		}
+	}
+
+	if calories > 0 {
+		activity.Calories = &calories
+	}
+	if weightedHR > 0 && activity.DurationSeconds > 0 {
+		avg := int(math.Round(float64(weightedHR) / activity.DurationSeconds))
+		activity.AvgHeartRate = &avg
+	}
+	if maxHR > 0 {
+		activity.MaxHeartRate = &maxHR
+	}
+
+	summarizeTrack(activity, points)
+	return activity, nil
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"backend/utils"
+)
+
+// StartAccountPurger pokreće pozadinski posao koji trajno briše naloge čiji je
+// period odlaganja brisanja istekao. Posao se zaustavlja kada se ctx otkaže;
+// wg se oslobađa tek kada se završi brisanje koje je u toku.
+func StartAccountPurger(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purgeDueAccounts(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// purgeDueAccounts briše sve naloge zakazane za brisanje do sada
+func purgeDueAccounts(ctx context.Context) {
+	rows, err := utils.DB.QueryContext(ctx, "SELECT id FROM users WHERE deletion_scheduled_for IS NOT NULL AND deletion_scheduled_for <= NOW()")
+	if err != nil {
+		slog.ErrorContext(ctx, "error querying accounts scheduled for deletion", "error", err)
+		return
+	}
+
+	var userIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			slog.ErrorContext(ctx, "error scanning account id", "error", err)
+			continue
+		}
+		userIDs = append(userIDs, id)
+	}
+	rows.Close()
+
+	for _, userID := range userIDs {
+		// Pri gašenju se ne započinje brisanje sledećeg naloga
+		if ctx.Err() != nil {
+			slog.InfoContext(ctx, "account purge interrupted by shutdown")
+			return
+		}
+		err := utils.PurgeUser(userID)
+		if errors.Is(err, utils.ErrPurgeNotDue) {
+			slog.InfoContext(ctx, "account deletion was cancelled, skipping purge", "user_id", userID)
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "error purging account", "user_id", userID, "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "account permanently deleted", "user_id", userID)
+	}
+}
@@ -0,0 +1,99 @@
+// Package logging podešava strukturirano logovanje (log/slog) i prenosi ID zahteva
+// i ID korisnika kroz context, da bi se našli u svakom redu loga tog zahteva.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Formati loga
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Setup postavlja podrazumevani slog logger. Redovi iz paketa log prolaze kroz isti handler.
+func Setup(format, level string) {
+	slog.SetDefault(slog.New(NewHandler(os.Stderr, format, level)))
+}
+
+// NewHandler kreira handler koji svakom redu dodaje request_id i user_id iz context-a
+func NewHandler(out io.Writer, format, level string) slog.Handler {
+	options := &slog.HandlerOptions{Level: ParseLevel(level)}
+	var handler slog.Handler = slog.NewJSONHandler(out, options)
+	if format == FormatText {
+		handler = slog.NewTextHandler(out, options)
+	}
+	return contextHandler{handler}
+}
+
+// ParseLevel pretvara debug/info/warn/error u slog nivo; nepoznata vrednost daje info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// requestInfo se deli kroz ceo lanac middleware-a jednog zahteva; Auth upisuje korisnika
+// naknadno, pa ga vidi i red pristupnog loga koji se piše spolja
+type requestInfo struct {
+	id     string
+	userID atomic.Int64
+}
+
+type contextKey struct{}
+
+// WithRequest vraća context sa ID-jem zahteva
+func WithRequest(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestInfo{id: requestID})
+}
+
+// RequestID vraća ID zahteva iz context-a, ili prazan string
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// SetUserID beleži prijavljenog korisnika za sve redove loga ovog zahteva
+func SetUserID(ctx context.Context, userID int) {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		info.userID.Store(int64(userID))
+	}
+}
+
+// contextHandler dodaje request_id i user_id iz context-a
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		record.AddAttrs(slog.String("request_id", info.id))
+		if userID := info.userID.Load(); userID != 0 {
+			record.AddAttrs(slog.Int64("user_id", userID))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -1,29 +1,16 @@
 package main
 
 import (
-	"log"
-	"net/http"
+	"log/slog"
+	"os"
 
-	"backend/routes"
-	"backend/utils"
+	"backend/cli"
 )
 
 func main() {
-	// Inicijalizacija baze podataka
-	if err := utils.InitDB(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
-	}
-	defer utils.CloseDB()
-
-	// Podešavanje ruta
-	handler := routes.SetupRoutes()
-
-	// Pokretanje servera
-	port := ":8080"
-	log.Printf("🚀 Server starting on port %s", port)
-	log.Printf("📡 API endpoints available at http://localhost%s/api", port)
-	
-	if err := http.ListenAndServe(port, handler); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Bez argumenata pokreće se server; ostale komande su opisane u cli paketu
+	if err := cli.Run(os.Args[1:]); err != nil {
+		slog.Error("command failed", "error", err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTP zahtevi; route je šablon rute iz ServeMux-a, a ne putanja zahteva
+var (
+	HTTPRequests = NewCounterVec("http_requests_total",
+		"Broj HTTP zahteva po metodi, ruti i statusu.", "method", "route", "status")
+	HTTPRequestDuration = NewHistogramVec("http_request_duration_seconds",
+		"Trajanje HTTP zahteva u sekundama.", DefaultBuckets, "method", "route", "status")
+)
+
+// Pozivi Open Food Facts API-ja
+var (
+	FoodAPIRequests = NewCounterVec("food_api_requests_total",
+		"Broj poziva Open Food Facts API-ja po HTTP statusu (error ako odgovor nije stigao).", "status")
+	FoodAPIRequestDuration = NewHistogramVec("food_api_request_duration_seconds",
+		"Trajanje poziva Open Food Facts API-ja u sekundama.", DefaultBuckets)
+	FoodAPIErrors = NewCounterVec("food_api_errors_total",
+		"Neuspeli pozivi Open Food Facts API-ja: transport (mreža, timeout) ili status (5xx).", "reason")
+)
+
+// Poslovni brojači
+var (
+	Registrations = NewCounterVec("fitness_registrations_total",
+		"Broj uspešnih registracija.")
+	Logins = NewCounterVec("fitness_logins_total",
+		"Broj pokušaja prijave po ishodu (success, failure, locked, rate_limited, two_factor_required).", "result")
+	WorkoutsCreated = NewCounterVec("fitness_workouts_created_total",
+		"Broj kreiranih treninga po izvoru (manual, file, csv).", "source")
+)
+
+// RegisterDBStats registruje metrike pool-a konekcija; stats se poziva pri svakom preuzimanju
+func RegisterDBStats(stats func() sql.DBStats) {
+	gauge := func(name, help string, value func(s sql.DBStats) float64) {
+		NewGaugeFunc(name, help, func() float64 { return value(stats()) })
+	}
+	counter := func(name, help string, value func(s sql.DBStats) float64) {
+		NewCounterFunc(name, help, func() float64 { return value(stats()) })
+	}
+
+	gauge("db_pool_max_open_connections", "Najveći dozvoljeni broj otvorenih konekcija.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("db_pool_open_connections", "Broj otvorenih konekcija (u upotrebi i slobodnih).",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("db_pool_in_use_connections", "Broj konekcija u upotrebi.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("db_pool_idle_connections", "Broj slobodnih konekcija.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("db_pool_wait_count_total", "Broj čekanja na slobodnu konekciju.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("db_pool_wait_duration_seconds_total", "Ukupno vreme čekanja na slobodnu konekciju u sekundama.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("db_pool_max_idle_closed_total", "Broj konekcija zatvorenih zbog ograničenja slobodnih konekcija.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("db_pool_max_lifetime_closed_total", "Broj konekcija zatvorenih zbog isteka životnog veka.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
+
+// FoodAPITransport beleži broj, trajanje i greške poziva Open Food Facts API-ja
+func FoodAPITransport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		FoodAPIRequestDuration.Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			FoodAPIRequests.Inc("error")
+			FoodAPIErrors.Inc("transport")
+			return resp, err
+		}
+		FoodAPIRequests.Inc(strconv.Itoa(resp.StatusCode))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			FoodAPIErrors.Inc("status")
+		}
+		return resp, nil
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,275 @@
+// Package metrics beleži metrike servera i izlaže ih u Prometheus tekstualnom
+// formatu (verzija 0.0.4), bez spoljnih zavisnosti.
+package metrics
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets su granice histograma trajanja u sekundama
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metric je jedna metrika koja ume da se ispiše u tekstualnom formatu
+type metric interface {
+	write(out *strings.Builder)
+}
+
+var registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func register(m metric) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.metrics = append(registry.metrics, m)
+}
+
+// Handler vraća handler za /metrics. Ako je token zadat, zahteva Authorization: Bearer <token>.
+func Handler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if token != "" {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		var out strings.Builder
+		registry.mu.Lock()
+		for _, m := range registry.metrics {
+			m.write(&out)
+		}
+		registry.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(out.String()))
+	})
+}
+
+// labelSet su vrednosti labela jedne serije
+type labelSet []string
+
+func (l labelSet) key() string {
+	return strings.Join(l, "\xff")
+}
+
+// CounterVec je brojač sa labelama; bez labela se koristi kao običan brojač
+type CounterVec struct {
+	name, help string
+	labels     []string
+	mu         sync.Mutex
+	series     map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labels labelSet
+	value  float64
+}
+
+// NewCounterVec kreira i registruje brojač
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, series: make(map[string]*counterSeries)}
+	// Brojač bez labela se ispisuje i pre prvog povećanja
+	if len(labels) == 0 {
+		c.Add(0)
+	}
+	register(c)
+	return c
+}
+
+// Inc povećava brojač za 1
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add povećava brojač za value; negativne vrednosti se ignorišu
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	checkLabels(c.name, c.labels, labelValues)
+	if value < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := labelSet(labelValues).key()
+	s := c.series[key]
+	if s == nil {
+		s = &counterSeries{labels: append(labelSet(nil), labelValues...)}
+		c.series[key] = s
+	}
+	s.value += value
+}
+
+func (c *CounterVec) write(out *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(out, c.name, c.help, "counter")
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		writeSample(out, c.name, c.labels, s.labels, "", "", s.value)
+	}
+}
+
+// HistogramVec je histogram sa labelama
+type HistogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+	mu         sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels labelSet
+	counts []uint64 // broj opažanja po bucket-u (nekumulativno)
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec kreira i registruje histogram sa datim (rastućim) granicama
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		name: name, help: help, labels: labels,
+		buckets: append([]float64(nil), buckets...),
+		series:  make(map[string]*histogramSeries),
+	}
+	sort.Float64s(h.buckets)
+	if len(labels) == 0 {
+		h.series[""] = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+	}
+	register(h)
+	return h
+}
+
+// Observe beleži jedno opažanje
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	checkLabels(h.name, h.labels, labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := labelSet(labelValues).key()
+	s := h.series[key]
+	if s == nil {
+		s = &histogramSeries{labels: append(labelSet(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *HistogramVec) write(out *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(out, h.name, h.help, "histogram")
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(out, h.name+"_bucket", h.labels, s.labels, "le", formatFloat(bound), float64(cumulative))
+		}
+		writeSample(out, h.name+"_bucket", h.labels, s.labels, "le", "+Inf", float64(s.count))
+		writeSample(out, h.name+"_sum", h.labels, s.labels, "", "", s.sum)
+		writeSample(out, h.name+"_count", h.labels, s.labels, "", "", float64(s.count))
+	}
+}
+
+// funcMetric je metrika bez labela čija se vrednost čita pri svakom preuzimanju
+type funcMetric struct {
+	name, help, kind string
+	value            func() float64
+}
+
+// NewGaugeFunc registruje gauge čiju vrednost vraća fn
+func NewGaugeFunc(name, help string, fn func() float64) {
+	register(&funcMetric{name: name, help: help, kind: "gauge", value: fn})
+}
+
+// NewCounterFunc registruje brojač čiju vrednost vraća fn (vrednost ne sme da opada)
+func NewCounterFunc(name, help string, fn func() float64) {
+	register(&funcMetric{name: name, help: help, kind: "counter", value: fn})
+}
+
+func (f *funcMetric) write(out *strings.Builder) {
+	writeHeader(out, f.name, f.help, f.kind)
+	writeSample(out, f.name, nil, nil, "", "", f.value())
+}
+
+func checkLabels(name string, labels []string, values []string) {
+	if len(labels) != len(values) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", name, len(labels), len(values)))
+	}
+}
+
+func sortedKeys[T any](series map[string]T) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeHeader(out *strings.Builder, name, help, kind string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, strings.ReplaceAll(help, "\n", " "), name, kind)
+}
+
+// writeSample ispisuje jedan red; extraName/extraValue je dodatna labela (le kod histograma)
+func writeSample(out *strings.Builder, name string, labels []string, values labelSet, extraName, extraValue string, value float64) {
+	out.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		out.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			fmt.Fprintf(out, "%s=\"%s\"", label, escapeLabel(values[i]))
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				out.WriteByte(',')
+			}
+			fmt.Fprintf(out, "%s=\"%s\"", extraName, extraValue)
+		}
+		out.WriteByte('}')
+	}
+	out.WriteByte(' ')
+	out.WriteString(formatFloat(value))
+	out.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
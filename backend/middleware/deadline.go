@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ExtendDeadlines produžava rok za čitanje tela i pisanje odgovora na timeout od
+// početka obrade. Koristi se za uvoz i izvoz, kojima serverski HTTP_READ_TIMEOUT i
+// HTTP_WRITE_TIMEOUT nisu dovoljni za fajlove do 25 MB i duge izvoze.
+func ExtendDeadlines(timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline := time.Now().Add(timeout)
+		rc := http.NewResponseController(w)
+		if err := rc.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.WarnContext(r.Context(), "failed to extend read deadline", "error", err)
+		}
+		if err := rc.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.WarnContext(r.Context(), "failed to extend write deadline", "error", err)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
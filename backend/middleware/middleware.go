@@ -2,13 +2,19 @@ package middleware
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
 	"net/http"
-	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"backend/auth"
+	"backend/logging"
+	"backend/metrics"
+	"backend/utils"
 )
 
 type contextKey string
@@ -16,19 +22,29 @@ type contextKey string
 const UserIDKey contextKey = "user_id"
 const EmailKey contextKey = "email"
 
-// CORS middleware
-func CORS(next http.Handler) http.Handler {
+// CORS middleware dozvoljava zahteve sa origin-a iz konfiguracije (ALLOWED_ORIGINS);
+// "*" dozvoljava sve
+func CORS(allowedOrigins []string, next http.Handler) http.Handler {
+	allowAny := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		allowed[origin] = true
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
-		if allowedOrigin := os.Getenv("ALLOWED_ORIGIN"); allowedOrigin != "" {
-			w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		} else if origin == "http://localhost:3000" || origin == "http://localhost:5173" || origin == "http://localhost:5174" {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-		} else {
+		if allowAny {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else if allowed[origin] {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
 		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Retry-After")
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -37,16 +53,76 @@ func CORS(next http.Handler) http.Handler {
 	})
 }
 
-// Logging middleware
+// validRequestID prihvata ID zahteva koji je poslao klijent ili proxy
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID middleware preuzima X-Request-ID iz zahteva ili generiše novi, vraća ga
+// u odgovoru i upisuje u context, da bi se našao u svakom redu loga zahteva
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(utils.RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(utils.RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequest(r.Context(), requestID)))
+	})
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// Logging middleware piše jedan red pristupnog loga po zahtevu
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 		next.ServeHTTP(wrapped, r)
-		log.Printf("%s %s %d %v", r.Method, r.RequestURI, wrapped.statusCode, time.Since(start))
+
+		level := slog.LevelInfo
+		if wrapped.statusCode >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(r.Context(), level, "request completed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", wrapped.statusCode,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"remote_addr", r.RemoteAddr,
+		)
 	})
 }
 
+// Metrics middleware beleži broj i trajanje zahteva. Ruta je šablon iz mux-a
+// (npr. /api/workouts/), a ne putanja zahteva, da broj serija ostane ograničen.
+func Metrics(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapped, r)
+
+		method := r.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+		status := strconv.Itoa(wrapped.statusCode)
+		metrics.HTTPRequests.Inc(method, route, status)
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), method, route, status)
+	})
+}
+
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -57,24 +133,30 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap omogućava http.ResponseController-u da dođe do Flush i rokova originalnog writer-a
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Auth middleware
 func Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Authorization required", http.StatusUnauthorized)
+			utils.JSONError(w, "Authorization required", http.StatusUnauthorized)
 			return
 		}
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
+			utils.JSONError(w, "Invalid token format", http.StatusUnauthorized)
 			return
 		}
 		claims, err := auth.ValidateToken(parts[1])
 		if err != nil {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			utils.JSONError(w, "Invalid token", http.StatusUnauthorized)
 			return
 		}
+		logging.SetUserID(r.Context(), claims.UserID)
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, EmailKey, claims.Email)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/config"
+	"backend/utils"
+)
+
+// bucketIdleTTL je vreme posle koga se pun, neaktivan bucket briše iz memorije
+const bucketIdleTTL = 10 * time.Minute
+
+// RateLimiter je token bucket po ključu (IP adresa, korisnik, nalog). Svaki ključ ima
+// kapacitet Requests tokena koji se dopunjava ravnomerno tokom Per.
+type RateLimiter struct {
+	name      string
+	capacity  float64
+	perSecond float64
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter kreira limiter za grupu ruta; vraća nil ako je ograničenje isključeno
+func NewRateLimiter(name string, rule config.RateRule) *RateLimiter {
+	if rule.Requests <= 0 || rule.Per <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		name:      name,
+		capacity:  float64(rule.Requests),
+		perSecond: float64(rule.Requests) / rule.Per.Seconds(),
+		buckets:   make(map[string]*bucket),
+		now:       time.Now,
+	}
+}
+
+// Allow troši jedan token za ključ. Ako tokena nema, vraća koliko treba čekati.
+// Nil limiter uvek dozvoljava.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: l.capacity, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.capacity, b.tokens+now.Sub(b.updated).Seconds()*l.perSecond)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+	return false, wait
+}
+
+// sweep povremeno briše bucket-e koji su se dopunili i dugo nisu korišćeni
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) > bucketIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// LimitByIP ograničava zahteve po IP adresi klijenta
+func LimitByIP(limiter *RateLimiter, trustProxy bool, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := limiter.Allow("ip:" + ClientIP(r, trustProxy)); !ok {
+			TooManyRequests(w, r, limiter.name, wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitByUser ograničava zahteve po prijavljenom korisniku; ide posle Auth middleware-a
+func LimitByUser(limiter *RateLimiter, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := limiter.Allow(fmt.Sprintf("user:%d", GetUserID(r))); !ok {
+			TooManyRequests(w, r, limiter.name, wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TooManyRequests šalje 429 sa Retry-After zaglavljem (u celim sekundama, zaokruženo naviše)
+func TooManyRequests(w http.ResponseWriter, r *http.Request, group string, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	slog.WarnContext(r.Context(), "rate limit exceeded", "group", group, "path", r.URL.Path, "retry_after", seconds)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	utils.JSONError(w, fmt.Sprintf("Too many requests, retry in %d seconds", seconds), http.StatusTooManyRequests)
+}
+
+// ClientIP vraća IP adresu klijenta. X-Forwarded-For se koristi samo kada je server
+// iza proxy-ja kome se veruje, i to poslednja adresa (ona koju je dodao proxy), jer
+// ostale klijent može sam da postavi.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			last := forwarded[strings.LastIndex(forwarded, ",")+1:]
+			if ip := strings.TrimSpace(last); net.ParseIP(ip) != nil {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
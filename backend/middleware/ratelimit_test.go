@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/config"
+)
+
+// fakeClock je sat koji se pomera ručno
+type fakeClock struct {
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestLimiter pravi limiter koji meri vreme satom iz testa
+func newTestLimiter(rule config.RateRule, clock *fakeClock) *RateLimiter {
+	limiter := NewRateLimiter("test", rule)
+	limiter.now = clock.now
+	return limiter
+}
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	for _, rule := range []config.RateRule{{Requests: 0, Per: time.Minute}, {Requests: 10}, {Requests: -1, Per: time.Minute}} {
+		if limiter := NewRateLimiter("test", rule); limiter != nil {
+			t.Errorf("NewRateLimiter(%+v) = %v, want nil", rule, limiter)
+		}
+	}
+	var limiter *RateLimiter
+	if ok, _ := limiter.Allow("ip:1.2.3.4"); !ok {
+		t.Fatal("nil limiter must allow every request")
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newTestLimiter(config.RateRule{Requests: 3, Per: 3 * time.Second}, clock)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("request %d denied within capacity", i+1)
+		}
+	}
+	ok, wait := limiter.Allow("a")
+	if ok || wait != time.Second {
+		t.Fatalf("Allow over capacity = %v, %v; want false, 1s", ok, wait)
+	}
+
+	// Ostali ključevi imaju sopstveni bucket
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Fatal("other key denied")
+	}
+
+	// Pola tokena posle 500 ms nije dovoljno; čeka se preostala polovina
+	clock.advance(500 * time.Millisecond)
+	if ok, wait := limiter.Allow("a"); ok || wait != 500*time.Millisecond {
+		t.Fatalf("Allow after 500ms = %v, %v; want false, 500ms", ok, wait)
+	}
+	clock.advance(500 * time.Millisecond)
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Fatal("request denied after a full token refilled")
+	}
+
+	// Dopuna ne prelazi kapacitet
+	clock.advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("request %d denied after idle period", i+1)
+		}
+	}
+	if ok, _ := limiter.Allow("a"); ok {
+		t.Fatal("bucket refilled above capacity")
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newTestLimiter(config.RateRule{Requests: 5, Per: time.Minute}, clock)
+
+	limiter.Allow("idle")
+	clock.advance(time.Minute)
+	limiter.Allow("active")
+	if len(limiter.buckets) != 2 {
+		t.Fatalf("got %d buckets, want 2", len(limiter.buckets))
+	}
+
+	// Posle bucketIdleTTL od poslednjeg korišćenja bucket se briše pri sledećem zahtevu
+	clock.advance(bucketIdleTTL)
+	limiter.Allow("active")
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Fatal("idle bucket was not swept")
+	}
+	if _, ok := limiter.buckets["active"]; !ok {
+		t.Fatal("active bucket was swept")
+	}
+
+	// Čišćenje se ne radi češće od jednom u minutu
+	clock.advance(bucketIdleTTL + time.Second)
+	limiter.buckets["stale"] = &bucket{tokens: 5, updated: clock.t.Add(-2 * bucketIdleTTL)}
+	limiter.lastSweep = clock.t.Add(-30 * time.Second)
+	limiter.Allow("active")
+	if _, ok := limiter.buckets["stale"]; !ok {
+		t.Fatal("sweep ran less than a minute after the previous one")
+	}
+}
+
+func TestTooManyRequestsRetryAfter(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		want string
+	}{
+		{0, "1"},
+		{200 * time.Millisecond, "1"},
+		{time.Second, "1"},
+		{1001 * time.Millisecond, "2"},
+		{90 * time.Second, "90"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		TooManyRequests(w, httptest.NewRequest(http.MethodGet, "/api/workouts", nil), "test", tt.wait)
+		if w.Code != http.StatusTooManyRequests {
+			t.Errorf("wait %v: status = %d, want 429", tt.wait, w.Code)
+		}
+		if got := w.Header().Get("Retry-After"); got != tt.want {
+			t.Errorf("wait %v: Retry-After = %q, want %q", tt.wait, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+-- Brisanje osnovnih tabela (zavisne tabele pre users)
+DROP TABLE IF EXISTS progress;
+DROP TABLE IF EXISTS workouts;
+DROP TABLE IF EXISTS users;
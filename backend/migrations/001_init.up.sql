@@ -1,7 +1,5 @@
-
--- Kompletna inicijalizacija baze podataka
-CREATE DATABASE IF NOT EXISTS app_db;
-USE app_db;
+-- Osnovne tabele. Baza (DB_NAME) se kreira pri pokretanju servera, pa migracije
+-- ne biraju bazu same.
 
 -- Kreiranje tabele korisnika
 CREATE TABLE IF NOT EXISTS users (
@@ -0,0 +1 @@
+-- Kolona progress_date i njen indeks su deo šeme iz 001, pa se ne uklanjaju.
@@ -0,0 +1,26 @@
+-- Dodavanje kolone progress_date u tabelu progress i indeksa za bržu pretragu po datumu.
+-- Baze kreirane iz 001 već imaju oba, pa se izmene primenjuju samo ako nedostaju.
+DROP PROCEDURE IF EXISTS migrate_002_fix_progress_date;
+
+DELIMITER //
+CREATE PROCEDURE migrate_002_fix_progress_date()
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = DATABASE() AND table_name = 'progress' AND column_name = 'progress_date') THEN
+        ALTER TABLE progress ADD COLUMN progress_date DATE NOT NULL DEFAULT (CURDATE()) AFTER notes;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.statistics
+                   WHERE table_schema = DATABASE() AND table_name = 'progress' AND index_name = 'idx_progress_date') THEN
+        CREATE INDEX idx_progress_date ON progress(progress_date);
+    END IF;
+END //
+DELIMITER ;
+
+CALL migrate_002_fix_progress_date();
+DROP PROCEDURE migrate_002_fix_progress_date;
+
+-- Ažuriranje postojećih redova koji imaju nevalidne datume
+UPDATE progress
+SET progress_date = CURDATE()
+WHERE progress_date IS NULL OR progress_date = '0000-00-00';
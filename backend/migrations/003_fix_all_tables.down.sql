@@ -0,0 +1 @@
+-- Kolone i indeksi iz ove migracije su deo šeme iz 001, pa se ne uklanjaju.
@@ -0,0 +1,39 @@
+-- Dodavanje nedostajućih kolona i indeksa u tabele kreirane pre 001.
+-- Svaka izmena se primenjuje samo ako kolona ili indeks ne postoje.
+DROP PROCEDURE IF EXISTS migrate_003_fix_all_tables;
+
+DELIMITER //
+CREATE PROCEDURE migrate_003_fix_all_tables()
+BEGIN
+    -- Popravka workouts tabele - dodavanje calories_burned
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = DATABASE() AND table_name = 'workouts' AND column_name = 'calories_burned') THEN
+        ALTER TABLE workouts ADD COLUMN calories_burned DECIMAL(10, 2) DEFAULT 0 AFTER duration;
+    END IF;
+
+    -- Popravka progress tabele - dodavanje progress_date
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = DATABASE() AND table_name = 'progress' AND column_name = 'progress_date') THEN
+        ALTER TABLE progress ADD COLUMN progress_date DATE NOT NULL DEFAULT (CURDATE()) AFTER notes;
+    END IF;
+
+    -- Indeksi za bržu pretragu po datumu
+    IF NOT EXISTS (SELECT 1 FROM information_schema.statistics
+                   WHERE table_schema = DATABASE() AND table_name = 'workouts' AND index_name = 'idx_workout_date') THEN
+        CREATE INDEX idx_workout_date ON workouts(workout_date);
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.statistics
+                   WHERE table_schema = DATABASE() AND table_name = 'progress' AND index_name = 'idx_progress_date') THEN
+        CREATE INDEX idx_progress_date ON progress(progress_date);
+    END IF;
+END //
+DELIMITER ;
+
+CALL migrate_003_fix_all_tables();
+DROP PROCEDURE migrate_003_fix_all_tables;
+
+-- Ažuriranje postojećih redova u progress tabeli
+UPDATE progress
+SET progress_date = CURDATE()
+WHERE progress_date IS NULL OR progress_date = '0000-00-00';
@@ -0,0 +1,5 @@
+ALTER TABLE workouts
+DROP COLUMN calories_method,
+DROP COLUMN activity_type;
+
+DROP TABLE IF EXISTS activity_types;
@@ -0,0 +1,38 @@
+-- Katalog aktivnosti sa MET vrednostima (Compendium of Physical Activities)
+CREATE TABLE IF NOT EXISTS activity_types (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(20) NOT NULL COMMENT 'cardio, strength, sport, other',
+    met DECIMAL(4, 1) NOT NULL CHECK (met > 0) COMMENT 'Metabolički ekvivalent'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO activity_types (code, name, category, met) VALUES
+    ('walking', 'Walking (5 km/h)', 'cardio', 3.5),
+    ('hiking', 'Hiking', 'cardio', 6.0),
+    ('running', 'Running (general)', 'cardio', 9.8),
+    ('running_easy', 'Running (8 km/h)', 'cardio', 8.3),
+    ('running_fast', 'Running (12 km/h)', 'cardio', 11.8),
+    ('cycling', 'Cycling (general)', 'cardio', 7.5),
+    ('cycling_indoor', 'Stationary cycling (moderate)', 'cardio', 6.8),
+    ('swimming', 'Swimming (freestyle, moderate)', 'cardio', 5.8),
+    ('rowing', 'Rowing machine (moderate)', 'cardio', 7.0),
+    ('elliptical', 'Elliptical trainer', 'cardio', 5.0),
+    ('jump_rope', 'Jump rope', 'cardio', 11.0),
+    ('hiit', 'HIIT / circuit training', 'cardio', 8.0),
+    ('strength_training', 'Weight lifting (moderate)', 'strength', 3.5),
+    ('strength_training_vigorous', 'Weight lifting (vigorous)', 'strength', 6.0),
+    ('bodyweight', 'Calisthenics (moderate)', 'strength', 3.8),
+    ('yoga', 'Yoga (hatha)', 'other', 2.5),
+    ('pilates', 'Pilates', 'other', 3.0),
+    ('stretching', 'Stretching', 'other', 2.3),
+    ('football', 'Football (casual)', 'sport', 7.0),
+    ('basketball', 'Basketball (general)', 'sport', 6.5),
+    ('tennis', 'Tennis (singles)', 'sport', 8.0)
+ON DUPLICATE KEY UPDATE name = VALUES(name), category = VALUES(category), met = VALUES(met);
+
+-- Tip aktivnosti i način na koji su kalorije dobijene (manual ili met)
+ALTER TABLE workouts
+ADD COLUMN activity_type VARCHAR(50) NULL AFTER description;
+
+ALTER TABLE workouts
+ADD COLUMN calories_method VARCHAR(20) NOT NULL DEFAULT 'manual' AFTER calories_burned;
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS workout_heart_rate_samples;
+
+ALTER TABLE users
+DROP COLUMN max_heart_rate;
+
+ALTER TABLE workouts
+DROP COLUMN max_heart_rate,
+DROP COLUMN avg_heart_rate,
+DROP COLUMN elevation_gain_m,
+DROP COLUMN distance_km;
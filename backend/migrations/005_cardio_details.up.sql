@@ -0,0 +1,26 @@
+-- Kardio detalji treninga: distanca, visinska razlika i puls
+ALTER TABLE workouts
+ADD COLUMN distance_km DECIMAL(8, 3) NULL COMMENT 'Distanca u km' AFTER calories_method;
+
+ALTER TABLE workouts
+ADD COLUMN elevation_gain_m DECIMAL(7, 1) NULL COMMENT 'Ukupan uspon u metrima' AFTER distance_km;
+
+ALTER TABLE workouts
+ADD COLUMN avg_heart_rate SMALLINT NULL COMMENT 'Prosečan puls (bpm)' AFTER elevation_gain_m;
+
+ALTER TABLE workouts
+ADD COLUMN max_heart_rate SMALLINT NULL COMMENT 'Maksimalan puls (bpm)' AFTER avg_heart_rate;
+
+-- Maksimalni puls korisnika, koristi se za računanje zona pulsa
+ALTER TABLE users
+ADD COLUMN max_heart_rate SMALLINT NULL COMMENT 'Maksimalni puls (bpm)' AFTER weight;
+
+-- Uzorci pulsa tokom treninga
+CREATE TABLE IF NOT EXISTS workout_heart_rate_samples (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    workout_id INT NOT NULL,
+    offset_seconds INT NOT NULL CHECK (offset_seconds >= 0) COMMENT 'Sekunde od početka treninga',
+    bpm SMALLINT NOT NULL CHECK (bpm > 0),
+    FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+    INDEX idx_workout_offset (workout_id, offset_seconds)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,6 @@
+DROP INDEX idx_workouts_user_fingerprint ON workouts;
+
+ALTER TABLE workouts
+DROP COLUMN import_fingerprint,
+DROP COLUMN started_at,
+DROP COLUMN source;
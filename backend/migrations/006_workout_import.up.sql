@@ -0,0 +1,11 @@
+-- Podaci o uvozu treninga iz fajlova sa uređaja (GPX, TCX)
+ALTER TABLE workouts
+ADD COLUMN source VARCHAR(20) NULL COMMENT 'Izvor uvoza: gpx, tcx' AFTER max_heart_rate;
+
+ALTER TABLE workouts
+ADD COLUMN started_at DATETIME NULL COMMENT 'Tačno vreme početka aktivnosti' AFTER source;
+
+ALTER TABLE workouts
+ADD COLUMN import_fingerprint CHAR(64) NULL COMMENT 'Otisak aktivnosti za prepoznavanje ponovnog uvoza' AFTER started_at;
+
+CREATE UNIQUE INDEX idx_workouts_user_fingerprint ON workouts(user_id, import_fingerprint);
@@ -0,0 +1,4 @@
+DROP INDEX idx_progress_user_fingerprint ON progress;
+
+ALTER TABLE progress
+DROP COLUMN import_fingerprint;
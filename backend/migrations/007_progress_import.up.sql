@@ -0,0 +1,5 @@
+-- Otisak uvezenog reda, da ponovni uvoz istog CSV fajla ne bi duplirao unose napretka
+ALTER TABLE progress
+ADD COLUMN import_fingerprint CHAR(64) NULL COMMENT 'Otisak reda za prepoznavanje ponovnog uvoza' AFTER notes;
+
+CREATE UNIQUE INDEX idx_progress_user_fingerprint ON progress(user_id, import_fingerprint);
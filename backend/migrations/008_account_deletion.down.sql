@@ -0,0 +1,5 @@
+DROP INDEX idx_users_deletion_scheduled ON users;
+
+ALTER TABLE users
+DROP COLUMN deletion_scheduled_for,
+DROP COLUMN deletion_requested_at;
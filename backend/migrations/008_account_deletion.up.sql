@@ -0,0 +1,8 @@
+-- Samostalno brisanje naloga sa periodom odlaganja
+ALTER TABLE users
+ADD COLUMN deletion_requested_at DATETIME NULL AFTER max_heart_rate;
+
+ALTER TABLE users
+ADD COLUMN deletion_scheduled_for DATETIME NULL COMMENT 'Nalog se trajno briše posle ovog trenutka' AFTER deletion_requested_at;
+
+CREATE INDEX idx_users_deletion_scheduled ON users(deletion_scheduled_for);
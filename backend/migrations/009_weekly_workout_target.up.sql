@@ -0,0 +1,3 @@
+-- Nedeljni cilj broja treninga koji korisnik sam postavlja
+ALTER TABLE users
+ADD COLUMN weekly_workout_target TINYINT UNSIGNED NULL COMMENT 'Željeni broj treninga nedeljno' AFTER max_heart_rate;
@@ -0,0 +1,20 @@
+-- Konkretni ciljevi korisnika sa istorijom. Aktivan je najviše jedan cilj po korisniku;
+-- pri postavljanju novog cilja prethodni dobija status 'replaced'.
+CREATE TABLE IF NOT EXISTS user_goals (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    goal_type VARCHAR(50) NOT NULL COMMENT 'lose_weight ili hypertrophy',
+    target_weight DECIMAL(5, 2) NULL COMMENT 'Ciljna težina u kg',
+    target_body_fat DECIMAL(5, 2) NULL COMMENT 'Ciljni procenat masti',
+    target_date DATE NULL COMMENT 'Rok za postizanje cilja',
+    weekly_workout_target TINYINT UNSIGNED NULL COMMENT 'Željeni broj treninga nedeljno',
+    start_weight DECIMAL(5, 2) NULL COMMENT 'Težina u trenutku postavljanja cilja',
+    start_body_fat DECIMAL(5, 2) NULL COMMENT 'Procenat masti u trenutku postavljanja cilja',
+    start_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'replaced', 'achieved')),
+    ended_at DATETIME NULL COMMENT 'Kada je cilj zamenjen ili ostvaren',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_goals_user_status (user_id, status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,10 @@
+-- Otključana dostignuća (bedževi). Pravila se nalaze u kodu, ovde se čuva samo
+-- koje je dostignuće korisnik otključao i kada.
+CREATE TABLE IF NOT EXISTS user_achievements (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    code VARCHAR(50) NOT NULL COMMENT 'Kod pravila iz koda aplikacije',
+    unlocked_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_user_achievements_user_code (user_id, code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,10 @@
+-- Dnevnik unosa vode
+CREATE TABLE IF NOT EXISTS water_logs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    amount_ml INT NOT NULL CHECK (amount_ml > 0) COMMENT 'Količina u ml',
+    logged_at DATETIME NOT NULL COMMENT 'Kada je voda uneta',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_water_logs_user_logged (user_id, logged_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS recovery_entries;
+DROP TABLE IF EXISTS sleep_entries;
@@ -0,0 +1,27 @@
+-- Dnevnik spavanja
+CREATE TABLE IF NOT EXISTS sleep_entries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    bed_time DATETIME NOT NULL COMMENT 'Odlazak na spavanje',
+    wake_time DATETIME NOT NULL COMMENT 'Buđenje',
+    quality TINYINT NOT NULL CHECK (quality BETWEEN 1 AND 5) COMMENT 'Subjektivni kvalitet sna (1-5)',
+    notes TEXT,
+    sleep_date DATE NOT NULL COMMENT 'Dan buđenja, za njega se računa spremnost',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_sleep_entries_user_date (user_id, sleep_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Dnevni pokazatelji oporavka: puls u mirovanju i upala mišića (jedan unos po danu)
+CREATE TABLE IF NOT EXISTS recovery_entries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    entry_date DATE NOT NULL,
+    resting_heart_rate SMALLINT NULL COMMENT 'Puls u mirovanju (bpm)',
+    soreness TINYINT NULL CHECK (soreness BETWEEN 1 AND 5) COMMENT 'Upala mišića (1 = nema, 5 = jaka)',
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_recovery_entries_user_date (user_id, entry_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS workout_exercises;
+
+ALTER TABLE workouts
+DROP COLUMN intensity;
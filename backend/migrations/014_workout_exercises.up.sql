@@ -0,0 +1,18 @@
+-- Subjektivni intenzitet treninga (RPE 1-10), koristi se za opterećenje (trajanje × intenzitet)
+ALTER TABLE workouts
+ADD COLUMN intensity TINYINT NULL CHECK (intensity BETWEEN 1 AND 10) COMMENT 'RPE 1-10' AFTER duration;
+
+-- Vežbe u okviru treninga, označene mišićnom grupom
+CREATE TABLE IF NOT EXISTS workout_exercises (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    workout_id INT NOT NULL,
+    position SMALLINT NOT NULL COMMENT 'Redosled vežbe u treningu',
+    name VARCHAR(100) NOT NULL,
+    muscle_group VARCHAR(30) NOT NULL,
+    sets SMALLINT NOT NULL CHECK (sets > 0),
+    reps SMALLINT NOT NULL CHECK (reps > 0) COMMENT 'Ponavljanja po seriji',
+    weight_kg DECIMAL(6, 2) NULL COMMENT 'Opterećenje po ponavljanju; NULL za vežbe sa sopstvenom težinom',
+    FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+    INDEX idx_workout_exercises_workout (workout_id),
+    INDEX idx_workout_exercises_muscle (muscle_group)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+-- Kolone iz ove migracije su deo šeme iz 001, a popravke podataka se ne mogu poništiti.
@@ -0,0 +1,39 @@
+-- Popravke šeme i podataka koje je server ranije radio pri svakom pokretanju
+-- (EnsureTablesExist, fixRoleColumn, fixExistingData). Baze kreirane iz 001 već
+-- imaju ispravnu šemu, pa se izmene primenjuju samo ako su potrebne.
+DROP PROCEDURE IF EXISTS migrate_015_legacy_schema_fixes;
+
+DELIMITER //
+CREATE PROCEDURE migrate_015_legacy_schema_fixes()
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'role') THEN
+        ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user' AFTER goal;
+    END IF;
+
+    -- Starije baze su imale role kao ENUM; aplikacija očekuje VARCHAR
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'role' AND data_type = 'enum') THEN
+        ALTER TABLE users MODIFY COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'height') THEN
+        ALTER TABLE users ADD COLUMN height DECIMAL(5, 2) NULL COMMENT 'Visina u cm' AFTER role;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'weight') THEN
+        ALTER TABLE users ADD COLUMN weight DECIMAL(5, 2) NULL COMMENT 'Težina u kg' AFTER height;
+    END IF;
+END //
+DELIMITER ;
+
+CALL migrate_015_legacy_schema_fixes();
+DROP PROCEDURE migrate_015_legacy_schema_fixes;
+
+-- Korisnici bez lozinke dobijaju prazan string i moraju da resetuju lozinku
+UPDATE users SET password = '' WHERE password IS NULL;
+
+-- Nevažeće uloge postaju obični korisnici
+UPDATE users SET role = 'user' WHERE role IS NULL OR role = '' OR role NOT IN ('admin', 'user', 'premium');
@@ -0,0 +1,5 @@
+-- Zaključavanje naloga posle uzastopnih neuspelih prijava (progresivno duže)
+ALTER TABLE users
+ADD COLUMN failed_login_count INT NOT NULL DEFAULT 0 COMMENT 'Uzastopne neuspele prijave',
+ADD COLUMN last_failed_login_at DATETIME NULL,
+ADD COLUMN locked_until DATETIME NULL COMMENT 'Prijava nije dozvoljena do ovog trenutka';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+
+ALTER TABLE users
+DROP COLUMN totp_last_step,
+DROP COLUMN totp_enabled,
+DROP COLUMN totp_secret;
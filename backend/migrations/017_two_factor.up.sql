@@ -0,0 +1,15 @@
+-- Dvofaktorska autentifikacija (TOTP) i jednokratni rezervni kodovi
+ALTER TABLE users
+ADD COLUMN totp_secret VARCHAR(64) NULL COMMENT 'Base32 TOTP tajna; postoji i pre potvrde uključivanja',
+ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN totp_last_step BIGINT NULL COMMENT 'Poslednji iskorišćeni TOTP korak, protiv ponovne upotrebe koda';
+
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL COMMENT 'SHA-256 heš koda',
+    used_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uniq_recovery_code (user_id, code_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE users
+DROP COLUMN totp_challenge_id;
@@ -0,0 +1,3 @@
+-- Challenge token za drugi korak prijave važi jednom: čuva se jti poslednjeg izdatog tokena
+ALTER TABLE users
+ADD COLUMN totp_challenge_id CHAR(32) NULL COMMENT 'jti poslednjeg challenge tokena; briše se posle uspešnog drugog koraka';
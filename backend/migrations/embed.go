@@ -0,0 +1,9 @@
+// Package migrations sadrži SQL migracije baze, ugrađene u binarni fajl servera
+package migrations
+
+import "embed"
+
+// FS sadrži sve NNN_ime.up.sql / NNN_ime.down.sql fajlove iz ovog foldera
+//
+//go:embed *.sql
+var FS embed.FS
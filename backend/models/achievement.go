@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Achievement je dostignuće sa stanjem za jednog korisnika
+type Achievement struct {
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Earned      bool       `json:"earned"`
+	UnlockedAt  *time.Time `json:"unlocked_at,omitempty"`
+	Current     float64    `json:"current"` // trenutna vrednost merila (npr. broj treninga)
+	Target      float64    `json:"target"`  // vrednost potrebna za otključavanje
+	Percent     float64    `json:"percent"` // napredak ka otključavanju (0-100)
+}
+
+// AchievementsResponse deli dostignuća na osvojena i zaključana
+type AchievementsResponse struct {
+	Earned []Achievement `json:"earned"`
+	Locked []Achievement `json:"locked"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// ActivityType predstavlja aktivnost iz kataloga sa MET vrednošću
+type ActivityType struct {
+	Code     string  `json:"code" db:"code"`
+	Name     string  `json:"name" db:"name"`
+	Category string  `json:"category" db:"category"` // cardio, strength, sport, other
+	MET      float64 `json:"met" db:"met"`
+}
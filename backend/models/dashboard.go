@@ -0,0 +1,15 @@
+package models
+
+// Dashboard predstavlja sažetak tekuće nedelje za početnu stranu
+type Dashboard struct {
+	WeekStart              string   `json:"week_start"` // ponedeljak tekuće nedelje (YYYY-MM-DD)
+	WeekEnd                string   `json:"week_end"`   // nedelja tekuće nedelje (YYYY-MM-DD)
+	WorkoutsThisWeek       int      `json:"workouts_this_week"`
+	MinutesThisWeek        int      `json:"minutes_this_week"`
+	CaloriesBurnedThisWeek float64  `json:"calories_burned_this_week"`
+	LatestWeight           *float64 `json:"latest_weight"`              // u kg, poslednje merenje
+	LatestWeightDate       *string  `json:"latest_weight_date"`         // datum poslednjeg merenja
+	WeightChangeVsLastWeek *float64 `json:"weight_change_vs_last_week"` // razlika u odnosu na merenje od pre nedelju dana
+	CurrentStreakDays      int      `json:"current_streak_days"`        // isto kao current_daily_streak na /api/streaks
+	DailyCalorieTarget     float64  `json:"daily_calorie_target"`       // procena prema cilju i težini; unos hrane se još ne beleži
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Statusi cilja
+const (
+	GoalStatusActive   = "active"
+	GoalStatusReplaced = "replaced"
+	GoalStatusAchieved = "achieved"
+)
+
+// Goal predstavlja konkretan cilj korisnika
+type Goal struct {
+	ID                  int        `json:"id" db:"id"`
+	GoalType            string     `json:"goal_type" db:"goal_type"`                       // lose_weight ili hypertrophy
+	TargetWeight        *float64   `json:"target_weight,omitempty" db:"target_weight"`     // u kg
+	TargetBodyFat       *float64   `json:"target_body_fat,omitempty" db:"target_body_fat"` // procenat
+	TargetDate          *string    `json:"target_date,omitempty" db:"target_date"`         // YYYY-MM-DD
+	WeeklyWorkoutTarget *int       `json:"weekly_workout_target,omitempty" db:"weekly_workout_target"`
+	StartWeight         *float64   `json:"start_weight,omitempty" db:"start_weight"`
+	StartBodyFat        *float64   `json:"start_body_fat,omitempty" db:"start_body_fat"`
+	StartDate           string     `json:"start_date" db:"start_date"`
+	Status              string     `json:"status" db:"status"` // active, replaced, achieved
+	EndedAt             *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+// GoalRequest predstavlja podatke za postavljanje novog cilja
+type GoalRequest struct {
+	GoalType            string   `json:"goal_type"` // opciono, podrazumevano trenutni cilj korisnika
+	TargetWeight        *float64 `json:"target_weight,omitempty"`
+	TargetBodyFat       *float64 `json:"target_body_fat,omitempty"`
+	TargetDate          *string  `json:"target_date,omitempty"` // YYYY-MM-DD
+	WeeklyWorkoutTarget *int     `json:"weekly_workout_target,omitempty"`
+}
+
+// GoalProgress je stanje aktivnog cilja izračunato iz merenja i treninga
+type GoalProgress struct {
+	Goal             Goal     `json:"goal"`
+	CurrentWeight    *float64 `json:"current_weight"`
+	CurrentBodyFat   *float64 `json:"current_body_fat"`
+	WeightPercent    *float64 `json:"weight_percent"`   // napredak ka ciljnoj težini (0-100)
+	BodyFatPercent   *float64 `json:"body_fat_percent"` // napredak ka ciljnom procentu masti (0-100)
+	PercentComplete  *float64 `json:"percent_complete"` // prosek merljivih ciljeva
+	ExpectedPercent  *float64 `json:"expected_percent"` // očekivani napredak prema proteklom vremenu do roka
+	DaysRemaining    *int     `json:"days_remaining"`
+	WorkoutsThisWeek int      `json:"workouts_this_week"`
+	WorkoutsLastWeek int      `json:"workouts_last_week"`
+	OnTrack          *bool    `json:"on_track"` // null ako cilj nema rok ni nedeljni cilj
+}
@@ -0,0 +1,18 @@
+package models
+
+// HealthReport je odgovor /health/live i /health/ready
+type HealthReport struct {
+	Status    string        `json:"status"` // ok, degraded (pala je neobavezna provera) ili fail
+	CheckedAt string        `json:"checked_at"`
+	Checks    []HealthCheck `json:"checks"`
+}
+
+// HealthCheck je rezultat provere jedne zavisnosti
+type HealthCheck struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`   // ok ili fail
+	Critical  bool    `json:"critical"` // pad kritične provere znači da server nije spreman
+	LatencyMs float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// CSVImportRowError opisuje grešku validacije jednog reda CSV fajla
+type CSVImportRowError struct {
+	Row     int    `json:"row"` // broj linije u fajlu (zaglavlje je linija 1)
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// CSVImportReport je rezultat (probnog) uvoza CSV fajla
+type CSVImportReport struct {
+	Entity            string              `json:"entity"` // workouts ili progress
+	DryRun            bool                `json:"dry_run"`
+	TotalRows         int                 `json:"total_rows"`
+	ValidRows         int                 `json:"valid_rows"`
+	Imported          int                 `json:"imported"`
+	SkippedDuplicates int                 `json:"skipped_duplicates"`
+	Errors            []CSVImportRowError `json:"errors"`
+}
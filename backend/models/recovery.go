@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// SleepEntry predstavlja jedan period spavanja
+type SleepEntry struct {
+	ID              int       `json:"id" db:"id"`
+	BedTime         time.Time `json:"bed_time" db:"bed_time"`
+	WakeTime        time.Time `json:"wake_time" db:"wake_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Quality         int       `json:"quality" db:"quality"` // 1-5
+	Notes           string    `json:"notes" db:"notes"`
+	SleepDate       string    `json:"sleep_date" db:"sleep_date"` // dan buđenja
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// SleepEntryRequest predstavlja podatke za unos spavanja
+type SleepEntryRequest struct {
+	BedTime  string `json:"bed_time" binding:"required"`  // RFC3339
+	WakeTime string `json:"wake_time" binding:"required"` // RFC3339
+	Quality  int    `json:"quality" binding:"required,min=1,max=5"`
+	Notes    string `json:"notes"`
+}
+
+// RecoveryEntry predstavlja dnevne pokazatelje oporavka
+type RecoveryEntry struct {
+	ID               int       `json:"id" db:"id"`
+	EntryDate        string    `json:"entry_date" db:"entry_date"`
+	RestingHeartRate *int      `json:"resting_heart_rate,omitempty" db:"resting_heart_rate"` // bpm
+	Soreness         *int      `json:"soreness,omitempty" db:"soreness"`                     // 1 = nema, 5 = jaka
+	Notes            string    `json:"notes" db:"notes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RecoveryEntryRequest predstavlja podatke za unos oporavka; isti dan se prepisuje
+type RecoveryEntryRequest struct {
+	EntryDate        string `json:"entry_date" binding:"required"`
+	RestingHeartRate *int   `json:"resting_heart_rate,omitempty"`
+	Soreness         *int   `json:"soreness,omitempty"`
+	Notes            string `json:"notes"`
+}
+
+// ReadinessComponent je jedan činilac ocene spremnosti
+type ReadinessComponent struct {
+	Name   string  `json:"name"`   // sleep, resting_heart_rate, soreness, training_load
+	Score  float64 `json:"score"`  // 0-100
+	Weight float64 `json:"weight"` // udeo u ukupnoj oceni pre normalizacije
+}
+
+// Readiness je dnevna ocena spremnosti za trening
+type Readiness struct {
+	Date               string               `json:"date"`
+	Score              *int                 `json:"score"` // 0-100, null ako nema podataka
+	Level              string               `json:"level"` // high, moderate, low, unknown
+	Components         []ReadinessComponent `json:"components"`
+	SleepMinutes       *int                 `json:"sleep_minutes"`
+	SleepQuality       *float64             `json:"sleep_quality"`
+	RestingHeartRate   *int                 `json:"resting_heart_rate"`
+	BaselineRestingHR  *float64             `json:"baseline_resting_heart_rate"` // prosek prethodnih 30 dana
+	Soreness           *int                 `json:"soreness"`
+	AcuteLoadMinutes   int                  `json:"acute_load_minutes"`   // minuti treninga u poslednjih 7 dana
+	ChronicLoadMinutes float64              `json:"chronic_load_minutes"` // nedeljni prosek poslednjih 28 dana
+	LoadRatio          *float64             `json:"load_ratio"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// StreakSummary predstavlja nizove treninga i doslednost korisnika
+type StreakSummary struct {
+	CurrentDailyStreak  int             `json:"current_daily_streak"` // uzastopni dani sa treningom, do danas ili juče
+	LongestDailyStreak  int             `json:"longest_daily_streak"`
+	CurrentWeeklyStreak int             `json:"current_weekly_streak"` // uzastopne nedelje sa bar jednim treningom
+	LongestWeeklyStreak int             `json:"longest_weekly_streak"`
+	WeeklyTarget        *int            `json:"weekly_target"`  // null ako korisnik nije postavio cilj
+	AdherenceRate       *float64        `json:"adherence_rate"` // udeo završenih nedelja u kojima je cilj ispunjen (0-1)
+	Weeks               []WeekAdherence `json:"weeks"`
+	Heatmap             []HeatmapDay    `json:"heatmap"`
+}
+
+// WeekAdherence je broj treninga u jednoj nedelji u odnosu na nedeljni cilj
+type WeekAdherence struct {
+	WeekStart string `json:"week_start"` // ponedeljak (YYYY-MM-DD)
+	Workouts  int    `json:"workouts"`
+	Minutes   int    `json:"minutes"`
+	TargetMet *bool  `json:"target_met"` // null ako cilj nije postavljen
+	Complete  bool   `json:"complete"`   // false za tekuću nedelju
+}
+
+// HeatmapDay je jedan dan kalendarskog prikaza aktivnosti
+type HeatmapDay struct {
+	Date     string `json:"date"`
+	Workouts int    `json:"workouts"`
+	Minutes  int    `json:"minutes"`
+}
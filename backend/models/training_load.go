@@ -0,0 +1,34 @@
+package models
+
+// MuscleGroupVolume je nedeljni obim treninga za jednu mišićnu grupu
+type MuscleGroupVolume struct {
+	MuscleGroup string  `json:"muscle_group"`
+	Sets        int     `json:"sets"`
+	Reps        int     `json:"reps"`
+	TonnageKg   float64 `json:"tonnage_kg"` // serije × ponavljanja × težina
+}
+
+// TrainingLoadWeek je opterećenje i obim po mišićnim grupama za jednu nedelju
+type TrainingLoadWeek struct {
+	WeekStart    string              `json:"week_start"`
+	Load         float64             `json:"load"` // zbir trajanje (min) × intenzitet (RPE)
+	Workouts     int                 `json:"workouts"`
+	MuscleGroups []MuscleGroupVolume `json:"muscle_groups"`
+}
+
+// TrainingLoadWarning upozorava na nagli skok opterećenja ili obima
+type TrainingLoadWarning struct {
+	Type        string `json:"type"` // load_spike ili volume_spike
+	MuscleGroup string `json:"muscle_group,omitempty"`
+	Message     string `json:"message"`
+}
+
+// TrainingLoadReport je pregled opterećenja, odnosa akutnog i hroničnog opterećenja i upozorenja
+type TrainingLoadReport struct {
+	AcuteLoad   float64               `json:"acute_load"`   // poslednjih 7 dana
+	ChronicLoad float64               `json:"chronic_load"` // nedeljni prosek poslednjih 28 dana
+	ACWR        *float64              `json:"acwr"`         // acute:chronic workload ratio
+	ACWRZone    string                `json:"acwr_zone"`    // low, optimal, elevated, high, unknown
+	Weeks       []TrainingLoadWeek    `json:"weeks"`
+	Warnings    []TrainingLoadWarning `json:"warnings"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// TwoFactorChallenge je odgovor na prijavu lozinkom kada nalog ima uključen 2FA;
+// prijava se završava slanjem challenge tokena i koda na /api/login/2fa
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"` // u sekundama
+}
+
+// TwoFactorLoginRequest predstavlja drugi korak prijave
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP kod ili rezervni kod
+}
+
+// TwoFactorStatus opisuje stanje 2FA za prijavljenog korisnika
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Available              bool `json:"available"` // samo premium i admin nalozi mogu da uključe 2FA
+	Pending                bool `json:"pending"`   // tajna je generisana, ali uključivanje nije potvrđeno
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetup sadrži novu TOTP tajnu i otpauth URI za QR kod
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest predstavlja zahtev potvrđen kodom iz authenticator aplikacije
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest predstavlja zahtev za isključivanje 2FA (lozinka i kod)
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP kod ili rezervni kod
+}
+
+// TwoFactorRecoveryCodes su novi rezervni kodovi; prikazuju se samo jednom
+type TwoFactorRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
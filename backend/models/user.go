@@ -4,16 +4,19 @@ import "time"
 
 // User predstavlja korisnika u sistemu
 type User struct {
-	ID        int       `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
-	Email     string    `json:"email" db:"email"`
-	Password  string    `json:"-" db:"password"` // Sakriveno od JSON-a
-	Goal      string    `json:"goal" db:"goal"`  // lose_weight ili hypertrophy
-	Role      string    `json:"role" db:"role"`  // admin, user, premium
-	Height    *float64  `json:"height,omitempty" db:"height"` // Visina u cm
-	Weight    *float64  `json:"weight,omitempty" db:"weight"` // Težina u kg
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID                   int        `json:"id" db:"id"`
+	Name                 string     `json:"name" db:"name"`
+	Email                string     `json:"email" db:"email"`
+	Password             string     `json:"-" db:"password"`                                              // Sakriveno od JSON-a
+	Goal                 string     `json:"goal" db:"goal"`                                               // lose_weight ili hypertrophy
+	Role                 string     `json:"role" db:"role"`                                               // admin, user, premium
+	Height               *float64   `json:"height,omitempty" db:"height"`                                 // Visina u cm
+	Weight               *float64   `json:"weight,omitempty" db:"weight"`                                 // Težina u kg
+	MaxHeartRate         *int       `json:"max_heart_rate,omitempty" db:"max_heart_rate"`                 // Maksimalni puls u bpm
+	WeeklyWorkoutTarget  *int       `json:"weekly_workout_target,omitempty" db:"weekly_workout_target"`   // Željeni broj treninga nedeljno
+	DeletionScheduledFor *time.Time `json:"deletion_scheduled_for,omitempty" db:"deletion_scheduled_for"` // Zakazano trajno brisanje naloga
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // RegisterRequest predstavlja podatke za registraciju
@@ -22,11 +25,20 @@ type RegisterRequest struct {
 	Email    string   `json:"email" binding:"required,email"`
 	Password string   `json:"password" binding:"required,min=6"`
 	Goal     string   `json:"goal" binding:"required,oneof=lose_weight hypertrophy"`
-	Role     string   `json:"role"` // Opciono, podrazumevano "user"
+	Role     string   `json:"role"`             // Opciono, podrazumevano "user"
 	Height   *float64 `json:"height,omitempty"` // Opciona visina u cm
 	Weight   *float64 `json:"weight,omitempty"` // Opciona težina u kg
 }
 
+// UpdateProfileRequest predstavlja podatke za izmenu profila (sva polja su opciona)
+type UpdateProfileRequest struct {
+	Name                *string  `json:"name,omitempty"`
+	Height              *float64 `json:"height,omitempty"`                // Visina u cm
+	Weight              *float64 `json:"weight,omitempty"`                // Težina u kg
+	MaxHeartRate        *int     `json:"max_heart_rate,omitempty"`        // Maksimalni puls u bpm
+	WeeklyWorkoutTarget *int     `json:"weekly_workout_target,omitempty"` // Željeni broj treninga nedeljno
+}
+
 // LoginRequest predstavlja podatke za prijavu
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -38,3 +50,14 @@ type LoginResponse struct {
 	User  *User  `json:"user"`
 	Token string `json:"token"`
 }
+
+// AccountDeletionRequest predstavlja zahtev za brisanje naloga (ponovna potvrda lozinkom)
+type AccountDeletionRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// AccountDeletionStatus opisuje stanje zakazanog brisanja naloga
+type AccountDeletionStatus struct {
+	DeletionRequestedAt  *time.Time `json:"deletion_requested_at"`
+	DeletionScheduledFor *time.Time `json:"deletion_scheduled_for"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// WaterLog predstavlja jedan unos vode
+type WaterLog struct {
+	ID        int       `json:"id" db:"id"`
+	AmountML  int       `json:"amount_ml" db:"amount_ml"`
+	LoggedAt  time.Time `json:"logged_at" db:"logged_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// WaterLogRequest predstavlja podatke za unos vode
+type WaterLogRequest struct {
+	AmountML int     `json:"amount_ml" binding:"required,min=1"`
+	LoggedAt *string `json:"logged_at,omitempty"` // RFC3339, podrazumevano sada
+}
+
+// HydrationDay je dnevni zbir unosa vode u odnosu na dnevni cilj
+type HydrationDay struct {
+	Date           string  `json:"date"`
+	TotalML        int     `json:"total_ml"`
+	TargetML       int     `json:"target_ml"` // iz telesne težine i minuta treninga tog dana
+	Percent        float64 `json:"percent"`
+	Entries        int     `json:"entries"`
+	WorkoutMinutes int     `json:"workout_minutes"`
+}
+
+// HydrationWeek je nedeljni pregled unosa vode (ponedeljak - nedelja)
+type HydrationWeek struct {
+	WeekStart     string         `json:"week_start"`
+	WeekEnd       string         `json:"week_end"`
+	TotalML       int            `json:"total_ml"`
+	TargetML      int            `json:"target_ml"`
+	AverageML     int            `json:"average_ml"` // prosek po danu do danas
+	DaysTargetMet int            `json:"days_target_met"`
+	Days          []HydrationDay `json:"days"`
+}
@@ -2,24 +2,90 @@ package models
 
 import "time"
 
+// Načini na koje su kalorije treninga dobijene
+const (
+	CaloriesMethodManual = "manual" // korisnik je uneo vrednost
+	CaloriesMethodMET    = "met"    // procenjeno iz MET vrednosti aktivnosti
+	CaloriesMethodDevice = "device" // zabeleženo na uređaju (uvoz iz fajla)
+)
+
 // model za trening
 type Workout struct {
-	ID             int       `json:"id" db:"id"`
-	UserID         int       `json:"user_id" db:"user_id"`
-	Name           string    `json:"name" db:"name"`
-	Description    string    `json:"description" db:"description"`
-	Duration       int       `json:"duration" db:"duration"` // u minutima
-	CaloriesBurned float64   `json:"calories_burned" db:"calories_burned"`
-	WorkoutDate    time.Time `json:"workout_date" db:"workout_date"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID             int               `json:"id" db:"id"`
+	UserID         int               `json:"user_id" db:"user_id"`
+	Name           string            `json:"name" db:"name"`
+	Description    string            `json:"description" db:"description"`
+	ActivityType   string            `json:"activity_type,omitempty" db:"activity_type"`
+	Duration       int               `json:"duration" db:"duration"`             // u minutima
+	Intensity      *int              `json:"intensity,omitempty" db:"intensity"` // RPE 1-10
+	CaloriesBurned float64           `json:"calories_burned" db:"calories_burned"`
+	CaloriesMethod string            `json:"calories_method" db:"calories_method"` // manual, met ili device
+	DistanceKm     *float64          `json:"distance_km,omitempty" db:"distance_km"`
+	ElevationGainM *float64          `json:"elevation_gain_m,omitempty" db:"elevation_gain_m"`
+	AvgHeartRate   *int              `json:"avg_heart_rate,omitempty" db:"avg_heart_rate"`
+	MaxHeartRate   *int              `json:"max_heart_rate,omitempty" db:"max_heart_rate"`
+	Cardio         *CardioMetrics    `json:"cardio,omitempty"` // izvedene metrike, ne čuvaju se u bazi
+	Exercises      []WorkoutExercise `json:"exercises,omitempty"`
+	Source         string            `json:"source,omitempty" db:"source"` // gpx, tcx, fit ili csv ako je trening uvezen iz fajla
+	StartedAt      *time.Time        `json:"started_at,omitempty" db:"started_at"`
+	WorkoutDate    time.Time         `json:"workout_date" db:"workout_date"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
 }
 
 // model za zahtev za kreiranje/azuriranje treniga
 type WorkoutRequest struct {
-	Name           string  `json:"name" binding:"required"`
-	Description    string  `json:"description"`
-	Duration       int     `json:"duration" binding:"required,min=1"`
-	CaloriesBurned float64 `json:"calories_burned" binding:"required,min=0"`
-	WorkoutDate    string  `json:"workout_date" binding:"required"`
+	Name             string            `json:"name" binding:"required"`
+	Description      string            `json:"description"`
+	ActivityType     string            `json:"activity_type"` // kod iz kataloga aktivnosti, opciono
+	Duration         int               `json:"duration" binding:"required,min=1"`
+	Intensity        *int              `json:"intensity,omitempty" binding:"omitempty,min=1,max=10"` // RPE 1-10
+	CaloriesBurned   *float64          `json:"calories_burned,omitempty" binding:"omitempty,min=0"`  // ako nedostaje, procenjuje se iz MET vrednosti
+	DistanceKm       *float64          `json:"distance_km,omitempty" binding:"omitempty,min=0"`
+	ElevationGainM   *float64          `json:"elevation_gain_m,omitempty" binding:"omitempty,min=0"`
+	AvgHeartRate     *int              `json:"avg_heart_rate,omitempty"`
+	MaxHeartRate     *int              `json:"max_heart_rate,omitempty"`
+	HeartRateSamples []HeartRateSample `json:"heart_rate_samples,omitempty"` // ako je zadato, zamenjuje postojeće uzorke
+	Exercises        []WorkoutExercise `json:"exercises,omitempty"`          // ako je zadato, zamenjuje postojeće vežbe
+	WorkoutDate      string            `json:"workout_date" binding:"required"`
+}
+
+// WorkoutExercise predstavlja jednu vežbu u treningu
+type WorkoutExercise struct {
+	Name        string   `json:"name" db:"name"`
+	MuscleGroup string   `json:"muscle_group" db:"muscle_group"` // npr. chest, back, quads
+	Sets        int      `json:"sets" db:"sets"`
+	Reps        int      `json:"reps" db:"reps"`                     // ponavljanja po seriji
+	WeightKg    *float64 `json:"weight_kg,omitempty" db:"weight_kg"` // nil za vežbe sa sopstvenom težinom
+}
+
+// HeartRateSample predstavlja jedno merenje pulsa tokom treninga
+type HeartRateSample struct {
+	OffsetSeconds int `json:"offset_seconds" db:"offset_seconds"` // sekunde od početka treninga
+	BPM           int `json:"bpm" db:"bpm"`
+}
+
+// CardioMetrics sadrži metrike izvedene iz distance i pulsa treninga
+type CardioMetrics struct {
+	AvgPaceSecondsPerKm *float64        `json:"avg_pace_seconds_per_km,omitempty"`
+	AvgPace             string          `json:"avg_pace,omitempty"` // format m:ss po km
+	AvgSpeedKmh         *float64        `json:"avg_speed_kmh,omitempty"`
+	AvgHeartRate        *int            `json:"avg_heart_rate,omitempty"`
+	MaxHeartRate        *int            `json:"max_heart_rate,omitempty"`
+	UserMaxHeartRate    *int            `json:"user_max_heart_rate,omitempty"` // maksimalni puls korišćen za zone
+	HeartRateZones      []HeartRateZone `json:"heart_rate_zones,omitempty"`
+}
+
+// HeartRateZone predstavlja vreme provedeno u jednoj zoni pulsa
+type HeartRateZone struct {
+	Zone    int `json:"zone"` // 1-5
+	MinBPM  int `json:"min_bpm"`
+	MaxBPM  int `json:"max_bpm"`
+	Seconds int `json:"seconds"`
+}
+
+// WorkoutImportResponse je odgovor na uvoz treninga iz fajla
+type WorkoutImportResponse struct {
+	Workout   Workout `json:"workout"`
+	Duplicate bool    `json:"duplicate"` // true ako je ista aktivnost već bila uvezena
 }
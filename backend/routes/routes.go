@@ -3,39 +3,110 @@ package routes
 import (
 	"net/http"
 	"os"
-	"path/filepath"
 
+	"backend/config"
 	"backend/controllers"
+	"backend/docs"
+	"backend/metrics"
 	"backend/middleware"
+	"backend/utils"
 )
 
 // SetupRoutes konfiguriše sve rute
-func SetupRoutes() http.Handler {
+func SetupRoutes(cfg *config.Config) http.Handler {
 	mux := http.NewServeMux()
 
+	// Ograničenja po grupama ruta: auth po IP adresi, ostali API po IP adresi i po
+	// korisniku, a teški uvozi i izvozi dodatno po korisniku i sa dužim rokom za prenos
+	limits := cfg.RateLimit
+	authLimiter := middleware.NewRateLimiter("auth", limits.Auth)
+	apiLimiter := middleware.NewRateLimiter("api", limits.API)
+	heavyLimiter := middleware.NewRateLimiter("heavy", limits.Heavy)
+	protected := func(handler http.HandlerFunc) http.Handler {
+		return middleware.LimitByIP(apiLimiter, limits.TrustProxy,
+			middleware.Auth(middleware.LimitByUser(apiLimiter, handler)))
+	}
+	heavy := func(handler http.HandlerFunc) http.Handler {
+		transfer := middleware.ExtendDeadlines(cfg.HTTP.TransferTimeout, handler)
+		return protected(middleware.LimitByUser(heavyLimiter, transfer).ServeHTTP)
+	}
+
 	// Javne rute
-	mux.HandleFunc("/api/register", controllers.Register)
-	mux.HandleFunc("/api/login", controllers.Login)
+	mux.Handle("/api/register", middleware.LimitByIP(authLimiter, limits.TrustProxy, http.HandlerFunc(controllers.Register)))
+	mux.Handle("/api/login", middleware.LimitByIP(authLimiter, limits.TrustProxy, http.HandlerFunc(controllers.Login)))
+	mux.Handle("/api/login/2fa", middleware.LimitByIP(authLimiter, limits.TrustProxy, http.HandlerFunc(controllers.LoginTwoFactor)))
 
 	// Zaštićene rute - Autentifikacija
-	mux.Handle("/api/logout", middleware.Auth(http.HandlerFunc(controllers.Logout)))
-	mux.Handle("/api/profile", middleware.Auth(http.HandlerFunc(controllers.GetProfile)))
+	mux.Handle("/api/logout", protected(controllers.Logout))
+	mux.Handle("/api/profile", protected(controllers.GetProfile))
+	mux.Handle("/api/profile/update", protected(controllers.UpdateProfile))
+
+	// Zaštićene rute - Dvofaktorska autentifikacija (TOTP)
+	mux.Handle("/api/2fa", protected(controllers.GetTwoFactorStatus))
+	mux.Handle("/api/2fa/setup", protected(controllers.SetupTwoFactor))
+	mux.Handle("/api/2fa/enable", protected(controllers.EnableTwoFactor))
+	mux.Handle("/api/2fa/disable", protected(controllers.DisableTwoFactor))
+	mux.Handle("/api/2fa/recovery-codes", protected(controllers.RegenerateRecoveryCodes))
+
+	// Zaštićene rute - Dashboard
+	mux.Handle("/api/dashboard", protected(controllers.GetDashboard))
+	mux.Handle("/api/streaks", protected(controllers.GetStreaks))
+	mux.Handle("/api/training-load", protected(controllers.GetTrainingLoad))
+
+	// Zaštićene rute - Ciljevi
+	mux.Handle("/api/goals", protected(controllers.GetGoal))
+	mux.Handle("/api/goals/create", protected(controllers.CreateGoal))
+	mux.Handle("/api/goals/history", protected(controllers.GetGoalHistory))
+
+	// Zaštićene rute - Dostignuća
+	mux.Handle("/api/achievements", protected(controllers.GetAchievements))
 
 	// Zaštićene rute - Hrana i Meal Plan
-	mux.Handle("/api/food/search", middleware.Auth(http.HandlerFunc(controllers.SearchFood)))
-	mux.Handle("/api/meal-plan", middleware.Auth(http.HandlerFunc(controllers.GenerateMealPlan)))
+	mux.Handle("/api/food/search", protected(controllers.SearchFood))
+	mux.Handle("/api/meal-plan", protected(controllers.GenerateMealPlan))
 
 	// Zaštićene rute - Treninzi (GET, POST, PUT, DELETE)
-	mux.Handle("/api/workouts", middleware.Auth(http.HandlerFunc(controllers.GetWorkouts)))
-	mux.Handle("/api/workouts/create", middleware.Auth(http.HandlerFunc(controllers.CreateWorkout)))
-	mux.Handle("/api/workouts/update", middleware.Auth(http.HandlerFunc(controllers.UpdateWorkout)))
-	mux.Handle("/api/workouts/delete", middleware.Auth(http.HandlerFunc(controllers.DeleteWorkout)))
+	mux.Handle("/api/workouts", protected(controllers.GetWorkouts))
+	mux.Handle("/api/workouts/create", protected(controllers.CreateWorkout))
+	mux.Handle("/api/workouts/update", protected(controllers.UpdateWorkout))
+	mux.Handle("/api/workouts/delete", protected(controllers.DeleteWorkout))
+	mux.Handle("/api/workouts/heart-rate", protected(controllers.GetWorkoutHeartRate))
+	mux.Handle("/api/workouts/import", heavy(controllers.ImportWorkout))
+	mux.Handle("/api/activities", protected(controllers.GetActivities))
 
 	// Zaštićene rute - Napredak (GET, POST, PUT, DELETE)
-	mux.Handle("/api/progress", middleware.Auth(http.HandlerFunc(controllers.GetProgress)))
-	mux.Handle("/api/progress/create", middleware.Auth(http.HandlerFunc(controllers.CreateProgress)))
-	mux.Handle("/api/progress/update", middleware.Auth(http.HandlerFunc(controllers.UpdateProgress)))
-	mux.Handle("/api/progress/delete", middleware.Auth(http.HandlerFunc(controllers.DeleteProgress)))
+	mux.Handle("/api/progress", protected(controllers.GetProgress))
+	mux.Handle("/api/progress/create", protected(controllers.CreateProgress))
+	mux.Handle("/api/progress/update", protected(controllers.UpdateProgress))
+	mux.Handle("/api/progress/delete", protected(controllers.DeleteProgress))
+
+	// Zaštićene rute - Unos vode
+	mux.Handle("/api/water", protected(controllers.GetWaterLogs))
+	mux.Handle("/api/water/create", protected(controllers.CreateWaterLog))
+	mux.Handle("/api/water/delete", protected(controllers.DeleteWaterLog))
+	mux.Handle("/api/water/summary", protected(controllers.GetHydrationSummary))
+	mux.Handle("/api/water/weekly", protected(controllers.GetHydrationWeek))
+
+	// Zaštićene rute - San i oporavak
+	mux.Handle("/api/sleep", protected(controllers.GetSleepEntries))
+	mux.Handle("/api/sleep/create", protected(controllers.CreateSleepEntry))
+	mux.Handle("/api/sleep/delete", protected(controllers.DeleteSleepEntry))
+	mux.Handle("/api/recovery", protected(controllers.GetRecoveryEntries))
+	mux.Handle("/api/recovery/save", protected(controllers.SaveRecoveryEntry))
+	mux.Handle("/api/readiness", protected(controllers.GetReadiness))
+
+	// Zaštićene rute - Izvoz podataka (CSV)
+	mux.Handle("/api/export/workouts.csv", heavy(controllers.ExportWorkoutsCSV))
+	mux.Handle("/api/export/progress.csv", heavy(controllers.ExportProgressCSV))
+	mux.Handle("/api/export/bundle.zip", heavy(controllers.ExportBundle))
+
+	// Zaštićene rute - Uvoz podataka (CSV)
+	mux.Handle("/api/import/csv", heavy(controllers.ImportCSV))
+
+	// Zaštićene rute - Nalog (izvoz svih podataka i brisanje)
+	mux.Handle("/api/account/export", heavy(controllers.ExportAccount))
+	mux.Handle("/api/account/delete", protected(controllers.RequestAccountDeletion))
+	mux.Handle("/api/account/delete/cancel", protected(controllers.CancelAccountDeletion))
 
 	// Health check
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -43,13 +114,26 @@ func SetupRoutes() http.Handler {
 		w.Write([]byte("OK"))
 	})
 
-	// OpenAPI specifikacija za Swagger UI
+	// Provere za orkestraciju: live samo potvrđuje da proces radi, ready proverava zavisnosti
+	mux.HandleFunc("/health/live", controllers.LiveHealth)
+	mux.HandleFunc("/health/ready", controllers.ReadyHealth)
+
+	// Prometheus metrike; uz METRICS_TOKEN zahtevaju Bearer token
+	mux.Handle("/metrics", metrics.Handler(cfg.MetricsToken))
+
+	// OpenAPI specifikacija za Swagger UI. Ugrađena je u binarni fajl; OPENAPI_PATH
+	// zamenjuje je fajlom sa diska, da bi se izmene videle bez ponovnog build-a.
+	openAPIPath := cfg.OpenAPIPath
 	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
-		// Učitaj OpenAPI fajl sa diska
-		openAPIPath := filepath.Join("docs", "openapi.yaml")
-		content, err := os.ReadFile(openAPIPath)
+		var content []byte
+		var err error
+		if openAPIPath != "" {
+			content, err = os.ReadFile(openAPIPath)
+		} else {
+			content, err = docs.FS.ReadFile(docs.OpenAPIFile)
+		}
 		if err != nil {
-			http.Error(w, "Failed to load OpenAPI specification", http.StatusInternalServerError)
+			utils.JSONError(w, "Failed to load OpenAPI specification", http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "application/x-yaml")
@@ -57,8 +141,10 @@ func SetupRoutes() http.Handler {
 	})
 
 	// Primena middleware-a
-	handler := middleware.CORS(mux)
+	handler := middleware.CORS(cfg.AllowedOrigins, mux)
+	handler = middleware.Metrics(mux, handler)
 	handler = middleware.Logging(handler)
+	handler = middleware.RequestID(handler)
 
 	return handler
 }
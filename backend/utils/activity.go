@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+)
+
+// CaloriesFromMET primenjuje ACSM formulu (MET · 3.5 · kg / 200 · min) i zaokružuje
+// na dve decimale. Koriste je API i seed komanda, da bi generisani podaci odgovarali API-ju.
+func CaloriesFromMET(met, weightKg, minutes float64) float64 {
+	kcal := met * 3.5 * weightKg / 200 * minutes
+	return math.Round(kcal*100) / 100
+}
+
+// LoadActivityMETs učitava ceo katalog aktivnosti kao mapu kod -> MET
+func LoadActivityMETs() (map[string]float64, error) {
+	rows, err := DB.Query("SELECT code, met FROM activity_types")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load activity types: %w", err)
+	}
+	defer rows.Close()
+
+	mets := make(map[string]float64)
+	for rows.Next() {
+		var code string
+		var met float64
+		if err := rows.Scan(&code, &met); err != nil {
+			return nil, fmt.Errorf("failed to scan activity type: %w", err)
+		}
+		mets[code] = met
+	}
+	return mets, rows.Err()
+}
@@ -3,54 +3,56 @@ package utils
 import (
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+
+	"backend/config"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
 var DB *sql.DB
 
-// InitDB inicijalizuje konekciju sa bazom podataka
-func InitDB() error {
-	// Uzimanje kredencijala iz environment promenljivih ili korišćenje podrazumevanih vrednosti
-	dbUser := getEnv("DB_USER", "root")
-	dbPassword := getEnv("DB_PASSWORD", "Vojislav123!")
-	dbHost := getEnv("DB_HOST", "127.0.0.1")
-	dbPort := getEnv("DB_PORT", "3306")
-	dbName := getEnv("DB_NAME", "app_db")
-
-	log.Printf("🔌 Pokušaj konekcije na MySQL server: %s@%s:%s", dbUser, dbHost, dbPort)
-
-	// Prvo, konekcija na MySQL server bez specificiranja baze
-	dsnWithoutDB := fmt.Sprintf("%s:%s@tcp(%s:%s)/?charset=utf8mb4&parseTime=True&loc=Local",
-		dbUser, dbPassword, dbHost, dbPort)
+// InitDB inicijalizuje konekciju sa bazom podataka. Šema se menja isključivo
+// verzionisanim migracijama: sa AutoMigrate (podrazumevano) primenjuju se pri
+// pokretanju, a inače se samo proverava da li je šema ažurna. StrictSchema
+// odbija pokretanje nad zaostalom šemom umesto da samo upozori.
+func InitDB(cfg config.DBConfig) error {
+	// Bez automatskih migracija server ne kreira ni bazu - ona mora već da postoji
+	if err := OpenDB(cfg, cfg.AutoMigrate); err != nil {
+		return err
+	}
 
-	tempDB, err := sql.Open("mysql", dsnWithoutDB)
-	if err != nil {
-		return fmt.Errorf("failed to connect to MySQL server: %w\n💡 Check if MySQL is running and credentials are correct", err)
+	if !cfg.AutoMigrate {
+		slog.Info("automatic migrations disabled (DB_AUTO_MIGRATE=false)")
+		return CheckSchemaVersion(cfg.StrictSchema)
 	}
-	defer tempDB.Close()
 
-	// Testiranje konekcije na MySQL server
-	if err := tempDB.Ping(); err != nil {
-		return fmt.Errorf("failed to ping MySQL server: %w\n💡 Possible issues:\n   - MySQL server is not running\n   - Wrong username/password\n   - Wrong host/port", err)
+	// Neuspela migracija zaustavlja pokretanje: server ne sme da radi nad delimično ažuriranom šemom
+	if err := RunMigrations(); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	log.Println("✅ Konektovano na MySQL server")
+	return nil
+}
 
-	// Kreiranje baze ako ne postoji
-	log.Printf("📦 Provera da li baza '%s' postoji...", dbName)
-	_, err = tempDB.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci", dbName))
-	if err != nil {
-		return fmt.Errorf("failed to create database: %w", err)
+// OpenDB otvara konekciju sa bazom, bez primene migracija.
+// Sa createIfMissing baza se kreira ako ne postoji.
+func OpenDB(cfg config.DBConfig, createIfMissing bool) error {
+	migrationsDir = cfg.MigrationsDir
+
+	slog.Info("connecting to MySQL", "user", cfg.User, "host", cfg.Host, "port", cfg.Port)
+
+	if createIfMissing {
+		if err := createDatabase(cfg); err != nil {
+			return err
+		}
 	}
-	log.Printf("✅ Baza '%s' spremna", dbName)
 
 	// Sada konekcija na specifičnu bazu
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
 
+	var err error
 	DB, err = sql.Open("mysql", dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open database connection: %w", err)
@@ -65,32 +67,35 @@ func InitDB() error {
 	DB.SetMaxOpenConns(25)
 	DB.SetMaxIdleConns(5)
 
-	log.Println("✅ Baza podataka uspešno konektovana")
+	slog.Info("database connected")
+	return nil
+}
 
-	// Automatsko osiguravanje da sve tabele postoje (fallback pristup)
-	if err := EnsureTablesExist(); err != nil {
-		log.Printf("⚠️  EnsureTablesExist failed: %v", err)
-		log.Println("🔄 Pokušavanje sa RunMigrations...")
-		// Pokušaj sa migracijama ako EnsureTablesExist ne uspe
-		if err := RunMigrations(); err != nil {
-			return fmt.Errorf("failed to run migrations: %w", err)
-		}
-	} else {
-		// Ako EnsureTablesExist uspe, pokreni migracije za dodatne izmene
-		if err := RunMigrations(); err != nil {
-			log.Printf("⚠️  RunMigrations failed: %v (nastavlja se sa postojećim tabelama)", err)
-		}
+// createDatabase kreira bazu ako ne postoji, preko konekcije na MySQL server bez izabrane baze
+func createDatabase(cfg config.DBConfig) error {
+	dsnWithoutDB := fmt.Sprintf("%s:%s@tcp(%s:%d)/?charset=utf8mb4&parseTime=True&loc=Local",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port)
+
+	tempDB, err := sql.Open("mysql", dsnWithoutDB)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL server: %w\n💡 Check if MySQL is running and credentials are correct", err)
 	}
+	defer tempDB.Close()
 
-	return nil
-}
+	// Testiranje konekcije na MySQL server
+	if err := tempDB.Ping(); err != nil {
+		return fmt.Errorf("failed to ping MySQL server: %w\n💡 Possible issues:\n   - MySQL server is not running\n   - Wrong username/password\n   - Wrong host/port", err)
+	}
 
-// getEnv uzima environment promenljivu ili vraća podrazumevanu vrednost
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	slog.Info("connected to MySQL server")
+
+	// Ime baze je provereno u config paketu (samo slova, cifre i _)
+	_, err = tempDB.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci", cfg.Name))
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
 	}
-	return defaultValue
+	slog.Info("database ready", "database", cfg.Name)
+	return nil
 }
 
 // CloseDB zatvara konekciju sa bazom podataka
@@ -5,23 +5,27 @@ import (
 	"net/http"
 )
 
+// RequestIDHeader nosi ID zahteva; middleware ga postavlja na odgovor pre handler-a
+const RequestIDHeader = "X-Request-ID"
+
 // ErrorResponse predstavlja JSON odgovor sa greškom
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+	Error     string `json:"error"`
+	Message   string `json:"message,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
-// JSONError šalje JSON odgovor sa greškom
+// JSONError šalje JSON odgovor sa greškom i ID-jem zahteva, da bi se greška povezala sa logom
 func JSONError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.WriteHeader(statusCode)
-	
+
 	response := ErrorResponse{
-		Error:   http.StatusText(statusCode),
-		Message: message,
+		Error:     http.StatusText(statusCode),
+		Message:   message,
+		RequestID: w.Header().Get(RequestIDHeader),
 	}
-	
+
 	json.NewEncoder(w).Encode(response)
 }
-
-
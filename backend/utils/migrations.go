@@ -1,19 +1,53 @@
 package utils
 
 import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"io/fs"
+	"log/slog"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+
+	"backend/migrations"
 )
 
-// initMigrationsTable kreira tabelu za praćenje migracija ako ne postoji
+// Migration je jedna verzionisana migracija. Verzija je ime fajla bez sufiksa
+// .up.sql / .down.sql (npr. 004_activity_types), pa preimenovanje sufiksa ne menja verziju.
+type Migration struct {
+	Version  string
+	Up       string
+	Down     string
+	HasDown  bool
+	Checksum string // sha256 sadržaja up fajla
+}
+
+// MigrationState je stanje jedne migracije u odnosu na schema_migrations
+type MigrationState struct {
+	Version   string     `json:"version"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Modified  bool       `json:"modified"` // up fajl je izmenjen posle primene
+	Missing   bool       `json:"missing"`  // primenjena je u bazi, ali fajl ne postoji
+}
+
+// appliedMigration je red iz schema_migrations
+type appliedMigration struct {
+	appliedAt time.Time
+	checksum  sql.NullString
+}
+
+// initMigrationsTable kreira tabelu za praćenje migracija ako ne postoji i dodaje
+// kolonu checksum tabelama kreiranim pre nje (postojeći redovi dobijaju NULL)
 func initMigrationsTable() error {
 	createTableSQL := `
 	CREATE TABLE IF NOT EXISTS schema_migrations (
 		version VARCHAR(255) PRIMARY KEY,
+		checksum CHAR(64) NULL,
 		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 	`
@@ -22,393 +56,495 @@ func initMigrationsTable() error {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
-	return nil
-}
-
-// isMigrationApplied proverava da li je migracija već primenjena
-func isMigrationApplied(version string) (bool, error) {
-	var count int
-	err := DB.QueryRow(
-		"SELECT COUNT(*) FROM schema_migrations WHERE version = ?",
-		version,
-	).Scan(&count)
-
+	_, hasChecksum, err := migrationsTableState()
 	if err != nil {
-		return false, fmt.Errorf("failed to check migration status: %w", err)
+		return err
+	}
+	if !hasChecksum {
+		slog.Info("adding checksum column to schema_migrations")
+		if _, err := DB.Exec("ALTER TABLE schema_migrations ADD COLUMN checksum CHAR(64) NULL AFTER version"); err != nil {
+			return fmt.Errorf("failed to add checksum column: %w", err)
+		}
 	}
 
-	return count > 0, nil
+	return nil
 }
 
-// markMigrationApplied označava migraciju kao primenjenu
-func markMigrationApplied(version string) error {
-	_, err := DB.Exec(
-		"INSERT INTO schema_migrations (version) VALUES (?)",
-		version,
-	)
+// migrationsTableState proverava da li postoje tabela schema_migrations i njena checksum kolona,
+// bez izmena šeme
+func migrationsTableState() (exists, hasChecksum bool, err error) {
+	err = DB.QueryRow(
+		"SELECT COUNT(*) > 0, COALESCE(SUM(column_name = 'checksum'), 0) > 0 FROM information_schema.columns "+
+			"WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'",
+	).Scan(&exists, &hasChecksum)
 	if err != nil {
-		return fmt.Errorf("failed to mark migration as applied: %w", err)
+		return false, false, fmt.Errorf("failed to inspect migrations table: %w", err)
 	}
-	return nil
+	return exists, hasChecksum, nil
 }
 
-// RunMigrations izvršava sve migracije iz migrations/ foldera
-func RunMigrations() error {
-	log.Println("🔄 Pokretanje migracija baze podataka...")
+// migrationsDir je folder sa migracijama iz konfiguracije (MIGRATIONS_DIR); postavlja ga OpenDB
+var migrationsDir string
 
-	// Kreiranje tabele za praćenje migracija
-	if err := initMigrationsTable(); err != nil {
-		return fmt.Errorf("failed to initialize migrations table: %w", err)
+// migrationsFS vraća migracije ugrađene u binarni fajl. MIGRATIONS_DIR zamenjuje ih
+// folderom sa diska, da bi se tokom razvoja nove migracije probale bez ponovnog build-a.
+func migrationsFS() fs.FS {
+	if dir := migrationsDir; dir != "" {
+		slog.Info("loading migrations from directory", "dir", dir)
+		return os.DirFS(dir)
 	}
+	return migrations.FS
+}
 
-	// Pronalaženje svih SQL fajlova u migrations/ folderu
-	migrationsDir := "migrations"
-	entries, err := os.ReadDir(migrationsDir)
+// LoadMigrations čita parove NNN_ime.up.sql / NNN_ime.down.sql i vraća ih sortirane po verziji.
+// Down fajl nije obavezan, ali bez njega migracija ne može da se vrati.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
-		return fmt.Errorf("failed to read migrations directory: %w", err)
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
 	}
 
-	// Filtriranje i sortiranje migracija
-	var migrationFiles []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
-			migrationFiles = append(migrationFiles, entry.Name())
+	byVersion := make(map[string]*Migration)
+	get := func(version string) *Migration {
+		if byVersion[version] == nil {
+			byVersion[version] = &Migration{Version: version}
 		}
+		return byVersion[version]
 	}
 
-	if len(migrationFiles) == 0 {
-		log.Println("⚠️  Nema migracija za izvršavanje")
-		return nil
-	}
-
-	// Sortiranje po imenu (001_init.sql, 002_add_column.sql, itd.)
-	sort.Strings(migrationFiles)
-
-	// Izvršavanje svake migracije
-	for _, filename := range migrationFiles {
-		version := strings.TrimSuffix(filename, ".sql")
-
-		// Provera da li je migracija već primenjena
-		applied, err := isMigrationApplied(version)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, name)
 		if err != nil {
-			return fmt.Errorf("failed to check migration %s: %w", version, err)
+			return nil, fmt.Errorf("failed to read migration file %s: %w", name, err)
 		}
 
-		if applied {
-			log.Printf("⏭️  Migracija %s već je primenjena, preskače se", version)
-			continue
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			m := get(strings.TrimSuffix(name, ".up.sql"))
+			sum := sha256.Sum256(content)
+			m.Up, m.Checksum = string(content), hex.EncodeToString(sum[:])
+		case strings.HasSuffix(name, ".down.sql"):
+			m := get(strings.TrimSuffix(name, ".down.sql"))
+			m.Down, m.HasDown = string(content), true
+		default:
+			return nil, fmt.Errorf("migration file %s must end with .up.sql or .down.sql", name)
 		}
+	}
 
-		log.Printf("📝 Primena migracije: %s", filename)
-
-		// Čitanje migration fajla
-		migrationPath := filepath.Join(migrationsDir, filename)
-		migrationSQL, err := os.ReadFile(migrationPath)
-		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", filename, err)
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, m := range byVersion {
+		if m.Checksum == "" {
+			return nil, fmt.Errorf("migration %s has a down file but no up file", version)
 		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
 
-		// Podela SQL-a na pojedinačne naredbe
-		statements := splitSQL(string(migrationSQL))
+// loadAppliedMigrations vraća primenjene migracije iz schema_migrations.
+// Ako tabela još ne postoji, nijedna migracija nije primenjena.
+func loadAppliedMigrations() (map[string]appliedMigration, error) {
+	exists, hasChecksum, err := migrationsTableState()
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]appliedMigration)
+	if !exists {
+		return applied, nil
+	}
 
-		// Izvršavanje svake naredbe
-		for i, statement := range statements {
-			statement = strings.TrimSpace(statement)
-			if statement == "" || strings.HasPrefix(statement, "--") {
-				continue
-			}
+	checksumColumn := "checksum"
+	if !hasChecksum {
+		checksumColumn = "NULL"
+	}
+	rows, err := DB.Query("SELECT version, " + checksumColumn + ", applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	defer rows.Close()
 
-			// Preskakanje CREATE DATABASE ako već postoji
-			if strings.HasPrefix(strings.ToUpper(statement), "CREATE DATABASE") {
-				log.Println("⏭️  Preskakanje CREATE DATABASE (već postoji)")
-				continue
-			}
+	for rows.Next() {
+		var version string
+		var row appliedMigration
+		if err := rows.Scan(&version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = row
+	}
+	return applied, rows.Err()
+}
 
-			// Izvršavanje naredbe
-			if _, err := DB.Exec(statement); err != nil {
-				errStr := strings.ToLower(err.Error())
-				// Ignorisanje grešaka koje su očekivane (tabela/kolona/indeks već postoji)
-				if strings.Contains(errStr, "already exists") ||
-					strings.Contains(errStr, "duplicate column") ||
-					strings.Contains(errStr, "duplicate key name") ||
-					strings.Contains(errStr, "database exists") {
-					log.Printf("⏭️  Već postoji, preskače se: %s", statement[:min(50, len(statement))])
-					continue
-				}
-				log.Printf("❌ Greška pri izvršavanju migracije %s, naredba %d: %v", version, i+1, err)
-				log.Printf("Naredba: %s", statement[:min(100, len(statement))])
-				return fmt.Errorf("migration %s failed at statement %d: %w", version, i+1, err)
+// verifyChecksums upoređuje checksum primenjenih migracija sa fajlovima. Redovi bez
+// checksum-a (primenjeni pre uvođenja provere) dobijaju checksum trenutnog fajla preko exec.
+func verifyChecksums(migrations []Migration, applied map[string]appliedMigration, exec func(string, ...interface{}) (sql.Result, error)) error {
+	var modified []string
+	for _, m := range migrations {
+		row, ok := applied[m.Version]
+		if !ok {
+			continue
+		}
+		if !row.checksum.Valid {
+			if _, err := exec("UPDATE schema_migrations SET checksum = ? WHERE version = ?", m.Checksum, m.Version); err != nil {
+				return fmt.Errorf("failed to record checksum for %s: %w", m.Version, err)
 			}
+			slog.Info("recorded migration checksum", "version", m.Version)
+			continue
 		}
-
-		// Označavanje migracije kao primenjene
-		if err := markMigrationApplied(version); err != nil {
-			return fmt.Errorf("failed to mark migration %s as applied: %w", version, err)
+		if row.checksum.String != m.Checksum {
+			modified = append(modified, m.Version)
 		}
-
-		log.Printf("✅ Migracija %s uspešno primenjena", version)
 	}
 
-	log.Println("✅ Sve migracije uspešno završene")
+	if len(modified) > 0 {
+		for _, version := range modified {
+			slog.Error("migration modified after it was applied (checksum mismatch)", "version", version)
+		}
+		return fmt.Errorf("applied migrations were modified: %s; restore the original files and put schema changes in a new migration",
+			strings.Join(modified, ", "))
+	}
 	return nil
 }
 
-// splitSQL deli SQL string na pojedinačne naredbe
-func splitSQL(sql string) []string {
-	// Uklanjanje komentara i podela po tački-zapeti
-	lines := strings.Split(sql, "\n")
-	var cleanedLines []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// Preskakanje praznih linija i komentara
-		if line == "" || strings.HasPrefix(line, "--") {
-			continue
-		}
-		cleanedLines = append(cleanedLines, line)
-	}
+// migrationLockTimeout je koliko se čeka da druga instanca završi migracije
+const migrationLockTimeout = 5 * time.Minute
 
-	// Spajanje i podela po tački-zapeti
-	fullSQL := strings.Join(cleanedLines, " ")
-	statements := strings.Split(fullSQL, ";")
+// withMigrationLock izvršava fn dok drži MySQL named lock, da dve instance servera
+// (ili server i CLI) ne bi istovremeno primenjivale ili vraćale migracije. Lock
+// pripada sesiji, pa se uzima i oslobađa na istoj konekciji.
+func withMigrationLock(fn func() error) error {
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
 
-	var result []string
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt != "" {
-			result = append(result, stmt)
-		}
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK('schema_migrations', ?)", int(migrationLockTimeout.Seconds())).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
 	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("timed out after %s waiting for the migration lock held by another process", migrationLockTimeout)
+	}
+	defer func() {
+		var released sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK('schema_migrations')").Scan(&released); err != nil {
+			slog.Error("failed to release migration lock", "error", err)
+		}
+	}()
 
-	return result
+	return fn()
 }
 
-// min vraća minimum od dva cela broja
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+// RunMigrations primenjuje sve migracije koje još nisu primenjene
+func RunMigrations() error {
+	slog.Info("running database migrations")
+	return withMigrationLock(runMigrations)
 }
 
-// EnsureTablesExist proverava da li sve potrebne tabele postoje i kreira ih ako je potrebno
-func EnsureTablesExist() error {
-	log.Println("🔍 Provera da li sve tabele postoje...")
+func runMigrations() error {
 
-	// Provera i kreiranje users tabele
-	if err := ensureTable("users", createUsersTableSQL); err != nil {
-		return err
+	if err := initMigrationsTable(); err != nil {
+		return fmt.Errorf("failed to initialize migrations table: %w", err)
 	}
 
-	// Osiguravanje da users ima role kolonu (bez CHECK constraint-a da bi se izbegli problemi)
-	if err := ensureColumn("users", "role", "ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user' AFTER goal"); err != nil {
+	migrations, err := LoadMigrations(migrationsFS())
+	if err != nil {
 		return err
 	}
+	if len(migrations) == 0 {
+		slog.Warn("no migrations found")
+		return nil
+	}
 
-	// Osiguravanje da users ima height kolonu
-	if err := ensureColumn("users", "height", "ALTER TABLE users ADD COLUMN height DECIMAL(5, 2) NULL AFTER role"); err != nil {
+	applied, err := loadAppliedMigrations()
+	if err != nil {
 		return err
 	}
-
-	// Osiguravanje da users ima weight kolonu
-	if err := ensureColumn("users", "weight", "ALTER TABLE users ADD COLUMN weight DECIMAL(5, 2) NULL AFTER height"); err != nil {
+	if err := verifyChecksums(migrations, applied, DB.Exec); err != nil {
 		return err
 	}
 
-	// Popravka role kolone ako ima problema (uklanjanje CHECK constraint-a ako pravi probleme)
-	if err := fixRoleColumn(); err != nil {
-		log.Printf("⚠️  Warning: Could not fix role column: %v", err)
+	known := make(map[string]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+	}
+	for version := range applied {
+		if !known[version] {
+			slog.Warn("applied migration has no file", "version", version)
+		}
 	}
 
-	// Provera i kreiranje workouts tabele
-	if err := ensureTable("workouts", createWorkoutsTableSQL); err != nil {
-		return err
+	pending := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		slog.Info("applying migration", "version", m.Version)
+		if err := applyMigration(m.Version, m.Up, true, m.Checksum); err != nil {
+			return err
+		}
+		slog.Info("migration applied", "version", m.Version)
+		pending++
 	}
 
-	// Osiguravanje da workouts ima calories_burned kolonu
-	if err := ensureColumn("workouts", "calories_burned", "ALTER TABLE workouts ADD COLUMN calories_burned DECIMAL(10, 2) DEFAULT 0 AFTER duration"); err != nil {
-		return err
+	if pending == 0 {
+		slog.Info("database is up to date")
+	} else {
+		slog.Info("migrations applied", "count", pending)
 	}
+	return nil
+}
 
-	// Provera i kreiranje progress tabele
-	if err := ensureTable("progress", createProgressTableSQL); err != nil {
-		return err
+// RollbackMigrations vraća poslednjih steps primenjenih migracija, od najnovije ka starijim
+func RollbackMigrations(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1")
+	}
+	return withMigrationLock(func() error { return rollbackMigrations(steps) })
+}
+
+func rollbackMigrations(steps int) error {
+	if err := initMigrationsTable(); err != nil {
+		return fmt.Errorf("failed to initialize migrations table: %w", err)
 	}
 
-	// Osiguravanje da progress ima progress_date kolonu
-	if err := ensureColumn("progress", "progress_date", "ALTER TABLE progress ADD COLUMN progress_date DATE NOT NULL DEFAULT (CURDATE()) AFTER notes"); err != nil {
+	migrations, err := LoadMigrations(migrationsFS())
+	if err != nil {
+		return err
+	}
+	applied, err := loadAppliedMigrations()
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksums(migrations, applied, DB.Exec); err != nil {
 		return err
 	}
 
-	// Popravka postojećih problema sa podacima
-	if err := fixExistingData(); err != nil {
-		log.Printf("⚠️  Warning: Failed to fix existing data: %v", err)
-		// Ne propadati ako ovo baca grešku, samo logovati
+	byVersion := make(map[string]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	if steps > len(versions) {
+		steps = len(versions)
 	}
 
-	log.Println("✅ Sve tabele i kolone postoje")
+	for _, version := range versions[:steps] {
+		m, ok := byVersion[version]
+		if !ok {
+			return fmt.Errorf("cannot roll back %s: migration file not found", version)
+		}
+		if !m.HasDown {
+			return fmt.Errorf("cannot roll back %s: no %s.down.sql", version, version)
+		}
+
+		slog.Info("rolling back migration", "version", version)
+		if err := applyMigration(version, m.Down, false, ""); err != nil {
+			return err
+		}
+		slog.Info("migration rolled back", "version", version)
+	}
 	return nil
 }
 
-// fixExistingData popravlja uobičajene probleme sa podacima u postojećim tabelama
-func fixExistingData() error {
-	// Popravka NULL lozinki (postavljanje na prazan string, korisnici će morati da resetuju)
-	_, err := DB.Exec("UPDATE users SET password = '' WHERE password IS NULL")
+// MigrationStatus vraća stanje svih poznatih migracija, uključujući primenjene čiji fajl ne postoji.
+// Samo čita bazu, pa se može pozvati i kada su automatske migracije isključene.
+func MigrationStatus() ([]MigrationState, error) {
+	migrations, err := LoadMigrations(migrationsFS())
 	if err != nil {
-		log.Printf("⚠️  Could not fix NULL passwords: %v", err)
+		return nil, err
 	}
-
-	// Pokušaj popravke nevažećih uloga - ovo može da ne uspe zbog CHECK constraint-a
-	// Ako ne uspe, korisnici sa nevažećim ulogama će morati da se poprave ručno ili obrišu
-	_, err = DB.Exec("UPDATE users SET role = 'user' WHERE role IS NULL OR role = '' OR role NOT IN ('admin', 'user', 'premium')")
+	applied, err := loadAppliedMigrations()
 	if err != nil {
-		// Ovo je očekivano da ne uspe ako je CHECK constraint strog
-		// Logovati ali ne propadati - korisnici mogu biti popravljeni ručno
-		log.Printf("⚠️  Could not auto-fix invalid roles (CHECK constraint may block this). You may need to fix manually in MySQL.")
-		log.Printf("💡 To fix manually, run in MySQL: DELETE FROM users WHERE role NOT IN ('admin', 'user', 'premium') OR role IS NULL;")
+		return nil, err
 	}
 
-	return nil
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Version: m.Version}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.appliedAt
+			state.Applied, state.AppliedAt = true, &appliedAt
+			state.Modified = row.checksum.Valid && row.checksum.String != m.Checksum
+			delete(applied, m.Version)
+		}
+		states = append(states, state)
+	}
+	for version, row := range applied {
+		appliedAt := row.appliedAt
+		states = append(states, MigrationState{Version: version, Applied: true, AppliedAt: &appliedAt, Missing: true})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
 }
 
-// ensureTable checks if table exists and creates it if it doesn't
-func ensureTable(tableName, createSQL string) error {
-	var exists bool
-	err := DB.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?)",
-		tableName,
-	).Scan(&exists)
+// SchemaSummary je sažetak stanja šeme u odnosu na migracije koje server poznaje
+type SchemaSummary struct {
+	Current  string   // poslednja primenjena migracija čiji fajl postoji
+	Latest   string   // poslednja migracija servera
+	Pending  []string // neprimenjene migracije
+	Modified []string // migracije izmenjene posle primene
+	Missing  []string // primenjene migracije bez fajla (baza je novija od servera)
+}
+
+// UpToDate vraća true ako su sve migracije primenjene i nijedna nije izmenjena
+func (s SchemaSummary) UpToDate() bool {
+	return len(s.Pending) == 0 && len(s.Modified) == 0
+}
 
+// SummarizeSchema poredi primenjene migracije sa migracijama servera; samo čita bazu
+func SummarizeSchema() (SchemaSummary, error) {
+	var summary SchemaSummary
+	states, err := MigrationStatus()
 	if err != nil {
-		return fmt.Errorf("failed to check if table %s exists: %w", tableName, err)
+		return summary, err
 	}
 
-	if !exists {
-		log.Printf("📝 Creating table: %s", tableName)
-		if _, err := DB.Exec(createSQL); err != nil {
-			return fmt.Errorf("failed to create table %s: %w", tableName, err)
+	for _, state := range states {
+		switch {
+		case state.Missing:
+			summary.Missing = append(summary.Missing, state.Version)
+			continue
+		case !state.Applied:
+			summary.Pending = append(summary.Pending, state.Version)
+		case state.Modified:
+			summary.Modified = append(summary.Modified, state.Version)
 		}
-		log.Printf("✅ Table %s created", tableName)
-	} else {
-		log.Printf("✓ Table %s already exists", tableName)
+		if state.Applied {
+			summary.Current = state.Version
+		}
+		summary.Latest = state.Version
 	}
-
-	return nil
+	return summary, nil
 }
 
-// ensureColumn checks if column exists and creates it if it doesn't
-func ensureColumn(tableName, columnName, alterSQL string) error {
-	var exists bool
-	err := DB.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?)",
-		tableName, columnName,
-	).Scan(&exists)
-
+// CheckSchemaVersion proverava da li su sve migracije primenjene i nijedna nije izmenjena.
+// U strogom režimu zaostala šema je greška, inače se samo loguje upozorenje.
+func CheckSchemaVersion(strict bool) error {
+	summary, err := SummarizeSchema()
 	if err != nil {
-		return fmt.Errorf("failed to check if column %s.%s exists: %w", tableName, columnName, err)
+		return err
 	}
 
-	if !exists {
-		log.Printf("📝 Adding column %s to table %s", columnName, tableName)
-		if _, err := DB.Exec(alterSQL); err != nil {
-			// Ignorisanje grešaka "Duplikat kolone"
-			if strings.Contains(err.Error(), "Duplicate column") {
-				log.Printf("✓ Column %s.%s already exists", tableName, columnName)
-				return nil
-			}
-			return fmt.Errorf("failed to add column %s to table %s: %w", columnName, tableName, err)
-		}
-		log.Printf("✅ Column %s added to table %s", columnName, tableName)
-	} else {
-		log.Printf("✓ Column %s.%s already exists", tableName, columnName)
+	for _, version := range summary.Missing {
+		slog.Warn("applied migration has no file; database may be newer than the server", "version", version)
+	}
+	if summary.UpToDate() {
+		slog.Info("database schema is up to date", "version", summary.Current)
+		return nil
 	}
 
+	problem := fmt.Sprintf("database schema does not match the migrations: pending [%s], modified after apply [%s]",
+		strings.Join(summary.Pending, ", "), strings.Join(summary.Modified, ", "))
+	if strict {
+		return fmt.Errorf("%s; run the migrations or enable DB_AUTO_MIGRATE", problem)
+	}
+	slog.Warn(problem)
 	return nil
 }
 
-// fixRoleColumn pokušava da popravi strukturu role kolone
-func fixRoleColumn() error {
-	// Provera trenutne strukture kolone
-	var dataType, columnDefault, isNullable, columnType string
-	err := DB.QueryRow(
-		"SELECT DATA_TYPE, COLUMN_DEFAULT, IS_NULLABLE, COLUMN_TYPE FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'role'",
-	).Scan(&dataType, &columnDefault, &isNullable, &columnType)
-
+// applyMigration izvršava naredbe jedne migracije i beleži je u schema_migrations (up)
+// ili je briše odatle (down). Migracije bez DDL naredbi se izvršavaju u transakciji;
+// MySQL implicitno potvrđuje svaku DDL naredbu, pa se one izvršavaju redom na istoj konekciji.
+func applyMigration(version, script string, up bool, checksum string) error {
+	statements, err := splitSQL(script)
 	if err != nil {
-		return fmt.Errorf("failed to check role column: %w", err)
+		return fmt.Errorf("migration %s: %w", version, err)
 	}
 
-	log.Printf("🔍 Role column: type=%s, full_type=%s, default=%s, nullable=%s", dataType, columnType, columnDefault, isNullable)
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migration %s: failed to get connection: %w", version, err)
+	}
+	defer conn.Close()
 
-	// Ako je ENUM ili ima probleme sa CHECK constraint-om, modifikovati na jednostavan VARCHAR
-	if dataType == "enum" || strings.Contains(strings.ToLower(columnType), "check") {
-		log.Println("🔧 Role kolona je ENUM ili ima CHECK constraint, konvertovanje u VARCHAR...")
-		_, err = DB.Exec("ALTER TABLE users MODIFY COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'")
+	record := func(exec func(string, ...interface{}) (sql.Result, error)) error {
+		var err error
+		if up {
+			_, err = exec("INSERT INTO schema_migrations (version, checksum) VALUES (?, ?)", version, checksum)
+		} else {
+			_, err = exec("DELETE FROM schema_migrations WHERE version = ?", version)
+		}
 		if err != nil {
-			log.Printf("⚠️  Could not modify role column: %v", err)
-			return fmt.Errorf("failed to modify role column: %w", err)
+			return fmt.Errorf("migration %s: failed to update schema_migrations: %w", version, err)
 		}
-		log.Println("✅ Role kolona konvertovana u VARCHAR")
-	} else {
-		// Samo osigurati da je ispravna
-		_, err = DB.Exec("ALTER TABLE users MODIFY COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'")
+		return nil
+	}
+
+	if !containsDDL(statements) {
+		tx, err := conn.BeginTx(ctx, nil)
 		if err != nil {
-			log.Printf("⚠️  Could not modify role column (might already be correct): %v", err)
-		} else {
-			log.Println("✅ Struktura role kolone verifikovana")
+			return fmt.Errorf("migration %s: failed to start transaction: %w", version, err)
+		}
+		defer tx.Rollback()
+
+		for i, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				logFailedStatement(version, i, statement, err)
+				return fmt.Errorf("migration %s failed at statement %d (rolled back): %w", version, i+1, err)
+			}
+		}
+		if err := record(tx.Exec); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %s: failed to commit: %w", version, err)
 		}
+		return nil
 	}
 
-	return nil
+	exec := func(query string, args ...interface{}) (sql.Result, error) {
+		return conn.ExecContext(ctx, query, args...)
+	}
+	for i, statement := range statements {
+		if _, err := exec(statement); err != nil {
+			logFailedStatement(version, i, statement, err)
+			return fmt.Errorf("migration %s failed at statement %d; statements before it contain DDL and were not rolled back: %w",
+				version, i+1, err)
+		}
+	}
+	return record(exec)
+}
+
+// logFailedStatement loguje naredbu migracije koja nije uspela
+func logFailedStatement(version string, index int, statement string, err error) {
+	slog.Error("migration statement failed", "version", version, "statement_index", index+1,
+		"statement", statement[:min(200, len(statement))], "error", err)
+}
+
+// ddlKeywords su naredbe posle kojih MySQL implicitno potvrđuje transakciju.
+// CALL je ovde jer procedure u migracijama služe za uslovni DDL.
+var ddlKeywords = map[string]bool{
+	"CREATE": true, "ALTER": true, "DROP": true, "RENAME": true, "TRUNCATE": true, "CALL": true,
+}
+
+// containsDDL proverava da li neka od naredbi ne može da se izvrši u transakciji
+func containsDDL(statements []string) bool {
+	for _, statement := range statements {
+		fields := strings.Fields(statement)
+		if len(fields) > 0 && ddlKeywords[strings.ToUpper(fields[0])] {
+			return true
+		}
+	}
+	return false
 }
 
-// SQL naredbe za kreiranje tabela
-const createUsersTableSQL = `
-CREATE TABLE IF NOT EXISTS users (
-    id INT AUTO_INCREMENT PRIMARY KEY,
-    name VARCHAR(100) NOT NULL,
-    email VARCHAR(100) UNIQUE NOT NULL,
-    password VARCHAR(255) NOT NULL,
-    goal VARCHAR(50) NOT NULL CHECK (goal IN ('lose_weight', 'hypertrophy')),
-    role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('admin', 'user', 'premium')),
-    height DECIMAL(5, 2) NULL COMMENT 'Height in cm',
-    weight DECIMAL(5, 2) NULL COMMENT 'Weight in kg',
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    INDEX idx_email (email),
-    INDEX idx_role (role)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
-`
-
-const createWorkoutsTableSQL = `
-CREATE TABLE IF NOT EXISTS workouts (
-    id INT AUTO_INCREMENT PRIMARY KEY,
-    user_id INT NOT NULL,
-    name VARCHAR(255) NOT NULL,
-    description TEXT,
-    duration INT NOT NULL CHECK (duration > 0) COMMENT 'Duration in minutes',
-    calories_burned DECIMAL(10, 2) DEFAULT 0 CHECK (calories_burned >= 0),
-    workout_date DATE NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-    INDEX idx_user_id (user_id),
-    INDEX idx_workout_date (workout_date)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
-`
-
-const createProgressTableSQL = `
-CREATE TABLE IF NOT EXISTS progress (
-    id INT AUTO_INCREMENT PRIMARY KEY,
-    user_id INT NOT NULL,
-    weight DECIMAL(5, 2) NOT NULL CHECK (weight > 0) COMMENT 'Weight in kg',
-    body_fat DECIMAL(5, 2) DEFAULT 0 CHECK (body_fat >= 0 AND body_fat <= 100) COMMENT 'Body fat percentage',
-    muscle_mass DECIMAL(5, 2) DEFAULT 0 CHECK (muscle_mass >= 0) COMMENT 'Muscle mass in kg',
-    notes TEXT,
-    progress_date DATE NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-    INDEX idx_user_id (user_id),
-    INDEX idx_progress_date (progress_date)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
-`
+// min vraća minimum od dva cela broja
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_second.up.sql":   {Data: []byte("ALTER TABLE a ADD COLUMN b INT;")},
+		"001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"README.md":           {Data: []byte("nije migracija")},
+		"archive/003.up.sql":  {Data: []byte("SELECT 1;")},
+		"003_third.up.sql":    {Data: []byte("SELECT 1;")},
+		"003_third.down.sql":  {Data: []byte("SELECT 2;")},
+		"004_fourth.up.sql":   {Data: []byte("SELECT 4;")},
+		"004_fourth.down.sql": {Data: []byte("")},
+	}
+
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	var versions []string
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+	if got := strings.Join(versions, ","); got != "001_first,002_second,003_third,004_fourth" {
+		t.Fatalf("versions = %s", got)
+	}
+
+	first, second := migrations[0], migrations[1]
+	if first.Checksum != sha256Hex("CREATE TABLE a (id INT);") {
+		t.Errorf("checksum = %s, want sha256 of the up file", first.Checksum)
+	}
+	if !first.HasDown || first.Down != "DROP TABLE a;" {
+		t.Errorf("first down = %q (HasDown %v)", first.Down, first.HasDown)
+	}
+	if second.HasDown {
+		t.Errorf("second migration has no down file, got HasDown = true")
+	}
+	if !migrations[3].HasDown {
+		t.Errorf("empty down file must still count as present")
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"down without up", fstest.MapFS{"001_a.down.sql": {Data: []byte("DROP TABLE a;")}}, "has a down file but no up file"},
+		{"unknown suffix", fstest.MapFS{"001_a.sql": {Data: []byte("SELECT 1;")}}, "must end with .up.sql or .down.sql"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMigrations(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// recordingExec beleži upite umesto da ih izvrši nad bazom
+type recordingExec struct {
+	queries [][]interface{}
+	err     error
+}
+
+func (r *recordingExec) exec(query string, args ...interface{}) (sql.Result, error) {
+	r.queries = append(r.queries, append([]interface{}{query}, args...))
+	return nil, r.err
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	migrations := []Migration{
+		{Version: "001_first", Checksum: sha256Hex("one")},
+		{Version: "002_second", Checksum: sha256Hex("two")},
+		{Version: "003_pending", Checksum: sha256Hex("three")},
+	}
+	applied := map[string]appliedMigration{
+		"001_first":  {appliedAt: time.Now(), checksum: sql.NullString{String: sha256Hex("one"), Valid: true}},
+		"002_second": {appliedAt: time.Now()}, // primenjena pre uvođenja checksum-a
+	}
+
+	recorder := &recordingExec{}
+	if err := verifyChecksums(migrations, applied, recorder.exec); err != nil {
+		t.Fatalf("verifyChecksums: %v", err)
+	}
+	if len(recorder.queries) != 1 {
+		t.Fatalf("got %d queries, want one backfill: %v", len(recorder.queries), recorder.queries)
+	}
+	backfill := recorder.queries[0]
+	if !strings.HasPrefix(backfill[0].(string), "UPDATE schema_migrations SET checksum") ||
+		backfill[1] != sha256Hex("two") || backfill[2] != "002_second" {
+		t.Fatalf("backfill = %v", backfill)
+	}
+}
+
+func TestVerifyChecksumsModified(t *testing.T) {
+	migrations := []Migration{
+		{Version: "001_first", Checksum: sha256Hex("one")},
+		{Version: "002_second", Checksum: sha256Hex("two, edited")},
+		{Version: "003_third", Checksum: sha256Hex("three, edited")},
+	}
+	applied := map[string]appliedMigration{
+		"001_first":  {checksum: sql.NullString{String: sha256Hex("one"), Valid: true}},
+		"002_second": {checksum: sql.NullString{String: sha256Hex("two"), Valid: true}},
+		"003_third":  {checksum: sql.NullString{String: sha256Hex("three"), Valid: true}},
+	}
+
+	recorder := &recordingExec{}
+	err := verifyChecksums(migrations, applied, recorder.exec)
+	if err == nil || !strings.Contains(err.Error(), "002_second, 003_third") {
+		t.Fatalf("err = %v, want both modified versions listed", err)
+	}
+	if len(recorder.queries) != 0 {
+		t.Fatalf("unexpected queries: %v", recorder.queries)
+	}
+}
+
+func TestVerifyChecksumsBackfillError(t *testing.T) {
+	migrations := []Migration{{Version: "001_first", Checksum: sha256Hex("one")}}
+	applied := map[string]appliedMigration{"001_first": {}}
+
+	recorder := &recordingExec{err: errors.New("connection lost")}
+	err := verifyChecksums(migrations, applied, recorder.exec)
+	if err == nil || !strings.Contains(err.Error(), "failed to record checksum for 001_first") {
+		t.Fatalf("err = %v, want backfill error", err)
+	}
+}
+
+func TestContainsDDL(t *testing.T) {
+	tests := []struct {
+		statements []string
+		want       bool
+	}{
+		{[]string{"INSERT INTO a VALUES (1)", "UPDATE a SET id = 2"}, false},
+		{[]string{"INSERT INTO a VALUES (1)", "alter table a add column b int"}, true},
+		{[]string{"CALL add_column_if_missing()"}, true},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := containsDDL(tt.statements); got != tt.want {
+			t.Errorf("containsDDL(%q) = %v, want %v", tt.statements, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitSQL deli SQL skriptu na pojedinačne naredbe. Tačka-zarez unutar stringova,
+// identifikatora pod backtick-om i komentara ne završava naredbu. Podržana je
+// DELIMITER direktiva mysql klijenta, pa tela procedura mogu da sadrže tačka-zarez.
+// Komentari se uklanjaju, osim izvršnih /*! ... */ komentara.
+func splitSQL(script string) ([]string, error) {
+	var statements []string
+	var current strings.Builder
+	delimiter := ";"
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); {
+		// DELIMITER važi samo kao zaseban red između naredbi
+		if (i == 0 || script[i-1] == '\n') && strings.TrimSpace(current.String()) == "" {
+			line, next := readLine(script, i)
+			fields := strings.Fields(line)
+			if len(fields) > 0 && strings.EqualFold(fields[0], "DELIMITER") {
+				if len(fields) != 2 {
+					return nil, fmt.Errorf("invalid DELIMITER directive: %q", strings.TrimSpace(line))
+				}
+				delimiter = fields[1]
+				current.Reset()
+				i = next
+				continue
+			}
+		}
+
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end, err := quotedEnd(script, i)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(script[i:end])
+			i = end
+		case strings.HasPrefix(script[i:], "--") && (i+2 == len(script) || isSQLSpace(script[i+2])), c == '#':
+			_, next := readLine(script, i)
+			// Prelazak u novi red se zadržava da bi DELIMITER u sledećem redu bio prepoznat
+			current.WriteByte('\n')
+			i = next
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment starting at byte %d", i)
+			}
+			end += i + 4
+			if strings.HasPrefix(script[i:], "/*!") {
+				current.WriteString(script[i:end])
+			} else {
+				current.WriteByte(' ')
+			}
+			i = end
+		case strings.HasPrefix(script[i:], delimiter):
+			flush()
+			i += len(delimiter)
+		default:
+			current.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	return statements, nil
+}
+
+// quotedEnd vraća poziciju iza stringa ili identifikatora koji počinje na start.
+// Navodnik se ponavlja udvostručavanjem; u stringovima važi i escape kosom crtom.
+func quotedEnd(script string, start int) (int, error) {
+	quote := script[start]
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated %c-quoted text starting at byte %d", quote, start)
+}
+
+// readLine vraća red koji počinje na start (bez prelaska u novi red) i poziciju sledećeg reda
+func readLine(script string, start int) (string, int) {
+	end := strings.IndexByte(script[start:], '\n')
+	if end < 0 {
+		return script[start:], len(script)
+	}
+	return script[start : start+end], start + end + 1
+}
+
+func isSQLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "simple statements",
+			script: "CREATE TABLE a (id INT);\nINSERT INTO a VALUES (1);\n",
+			want:   []string{"CREATE TABLE a (id INT)", "INSERT INTO a VALUES (1)"},
+		},
+		{
+			name:   "no trailing semicolon",
+			script: "SELECT 1;\nSELECT 2",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "semicolon in single-quoted string",
+			script: "INSERT INTO a (name) VALUES ('a;b');SELECT 1;",
+			want:   []string{"INSERT INTO a (name) VALUES ('a;b')", "SELECT 1"},
+		},
+		{
+			name:   "semicolon in double-quoted string and backtick identifier",
+			script: "INSERT INTO `t;x` (name) VALUES (\"a;b\");",
+			want:   []string{"INSERT INTO `t;x` (name) VALUES (\"a;b\")"},
+		},
+		{
+			name:   "escaped and doubled quotes",
+			script: `INSERT INTO a VALUES ('it\'s;', 'it''s;');SELECT 1;`,
+			want:   []string{`INSERT INTO a VALUES ('it\'s;', 'it''s;')`, "SELECT 1"},
+		},
+		{
+			name:   "dash comment",
+			script: "-- uvodni komentar; sa tačka-zarezom\nSELECT 1; -- posle naredbe;\nSELECT 2;",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "double dash without space is not a comment",
+			script: "SELECT 5--1;",
+			want:   []string{"SELECT 5--1"},
+		},
+		{
+			name:   "hash comment",
+			script: "# komentar;\nSELECT 1;\n# još jedan\n",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "block comment",
+			script: "SELECT /* ; */ 1;",
+			want:   []string{"SELECT   1"},
+		},
+		{
+			name:   "executable comment is kept",
+			script: "/*!40101 SET NAMES utf8mb4 */;\nSELECT 1;",
+			want:   []string{"/*!40101 SET NAMES utf8mb4 */", "SELECT 1"},
+		},
+		{
+			name: "procedure body with DELIMITER",
+			script: "DROP PROCEDURE IF EXISTS p;\n" +
+				"DELIMITER //\n" +
+				"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND //\n" +
+				"DELIMITER ;\n" +
+				"CALL p();\n" +
+				"DROP PROCEDURE p;\n",
+			want: []string{
+				"DROP PROCEDURE IF EXISTS p",
+				"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND",
+				"CALL p()",
+				"DROP PROCEDURE p",
+			},
+		},
+		{
+			name:   "DELIMITER after comment line",
+			script: "-- procedura\nDELIMITER $$\nCREATE PROCEDURE p() BEGIN SELECT 1; END$$\nDELIMITER ;\n",
+			want:   []string{"CREATE PROCEDURE p() BEGIN SELECT 1; END"},
+		},
+		{
+			name:   "empty script",
+			script: " \n-- samo komentar\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitSQL(tt.script)
+			if err != nil {
+				t.Fatalf("splitSQL: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSQLErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"unterminated string", "INSERT INTO a VALUES ('abc);", "unterminated '-quoted text"},
+		{"unterminated identifier", "SELECT `abc;", "unterminated `-quoted text"},
+		{"unterminated comment", "SELECT 1; /* komentar", "unterminated comment"},
+		{"DELIMITER without value", "DELIMITER\nSELECT 1;", "invalid DELIMITER directive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := splitSQL(tt.script)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
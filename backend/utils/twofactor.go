@@ -0,0 +1,24 @@
+package utils
+
+import "fmt"
+
+// ClearTwoFactor isključuje dvofaktorsku autentifikaciju: briše TOTP tajnu i
+// rezervne kodove korisnika. Koriste je API i CLI komanda za korisnike koji su
+// izgubili uređaj i rezervne kodove.
+func ClearTwoFactor(userID int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = NULL, totp_challenge_id = NULL WHERE id = ?", userID,
+	); err != nil {
+		return fmt.Errorf("failed to disable two-factor: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM two_factor_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// ErrPurgeNotDue znači da nalog više nije zakazan za brisanje (korisnik je
+// u međuvremenu otkazao brisanje) ili rok još nije istekao
+var ErrPurgeNotDue = errors.New("account is not due for deletion")
+
+// UserDataTable opisuje tabelu sa podacima korisnika koja ulazi u izvoz naloga
+// i koja se briše pri trajnom brisanju naloga. Oba upita primaju user_id.
+type UserDataTable struct {
+	Name        string
+	ExportQuery string
+	DeleteQuery string
+}
+
+// UserDataTables su sve tabele sa podacima korisnika, u redosledu brisanja
+// (zavisne tabele pre roditeljskih). Nova funkcionalnost koja čuva podatke
+// korisnika mora da doda svoju tabelu ovde.
+var UserDataTables = []UserDataTable{
+	{
+		Name: "workout_heart_rate_samples",
+		ExportQuery: "SELECT s.workout_id, s.offset_seconds, s.bpm FROM workout_heart_rate_samples s " +
+			"JOIN workouts w ON w.id = s.workout_id WHERE w.user_id = ? ORDER BY s.workout_id, s.offset_seconds",
+		DeleteQuery: "DELETE s FROM workout_heart_rate_samples s JOIN workouts w ON w.id = s.workout_id WHERE w.user_id = ?",
+	},
+	{
+		Name: "workout_exercises",
+		ExportQuery: "SELECT e.workout_id, e.position, e.name, e.muscle_group, e.sets, e.reps, e.weight_kg FROM workout_exercises e " +
+			"JOIN workouts w ON w.id = e.workout_id WHERE w.user_id = ? ORDER BY e.workout_id, e.position",
+		DeleteQuery: "DELETE e FROM workout_exercises e JOIN workouts w ON w.id = e.workout_id WHERE w.user_id = ?",
+	},
+	{
+		Name: "workouts",
+		ExportQuery: "SELECT id, name, description, activity_type, duration, intensity, calories_burned, calories_method, distance_km, elevation_gain_m, " +
+			"avg_heart_rate, max_heart_rate, source, started_at, workout_date, created_at, updated_at FROM workouts WHERE user_id = ? ORDER BY workout_date, id",
+		DeleteQuery: "DELETE FROM workouts WHERE user_id = ?",
+	},
+	{
+		Name:        "progress",
+		ExportQuery: "SELECT id, weight, body_fat, muscle_mass, notes, progress_date, created_at, updated_at FROM progress WHERE user_id = ? ORDER BY progress_date, id",
+		DeleteQuery: "DELETE FROM progress WHERE user_id = ?",
+	},
+	{
+		Name: "goals",
+		ExportQuery: "SELECT id, goal_type, target_weight, target_body_fat, target_date, weekly_workout_target, start_weight, start_body_fat, start_date, status, ended_at, created_at, updated_at " +
+			"FROM user_goals WHERE user_id = ? ORDER BY id",
+		DeleteQuery: "DELETE FROM user_goals WHERE user_id = ?",
+	},
+	{
+		Name:        "water_logs",
+		ExportQuery: "SELECT id, amount_ml, logged_at, created_at FROM water_logs WHERE user_id = ? ORDER BY logged_at, id",
+		DeleteQuery: "DELETE FROM water_logs WHERE user_id = ?",
+	},
+	{
+		Name:        "sleep_entries",
+		ExportQuery: "SELECT id, bed_time, wake_time, quality, notes, sleep_date, created_at FROM sleep_entries WHERE user_id = ? ORDER BY sleep_date, id",
+		DeleteQuery: "DELETE FROM sleep_entries WHERE user_id = ?",
+	},
+	{
+		Name:        "recovery_entries",
+		ExportQuery: "SELECT id, entry_date, resting_heart_rate, soreness, notes, created_at, updated_at FROM recovery_entries WHERE user_id = ? ORDER BY entry_date",
+		DeleteQuery: "DELETE FROM recovery_entries WHERE user_id = ?",
+	},
+	{
+		Name:        "achievements",
+		ExportQuery: "SELECT code, unlocked_at FROM user_achievements WHERE user_id = ? ORDER BY unlocked_at, id",
+		DeleteQuery: "DELETE FROM user_achievements WHERE user_id = ?",
+	},
+	{
+		// Izvoze se samo datumi; heševi kodova i TOTP tajna ne napuštaju bazu
+		Name:        "two_factor_recovery_codes",
+		ExportQuery: "SELECT id, used_at, created_at FROM two_factor_recovery_codes WHERE user_id = ? ORDER BY id",
+		DeleteQuery: "DELETE FROM two_factor_recovery_codes WHERE user_id = ?",
+	},
+	{
+		Name: "profile",
+		ExportQuery: "SELECT id, name, email, goal, role, height, weight, max_heart_rate, weekly_workout_target, totp_enabled, deletion_requested_at, deletion_scheduled_for, created_at, updated_at " +
+			"FROM users WHERE id = ?",
+		DeleteQuery: "DELETE FROM users WHERE id = ?",
+	},
+}
+
+// PurgeUser trajno briše korisnika i sve njegove podatke u jednoj transakciji.
+// Zakazano brisanje se ponovo proverava uz zaključavanje reda, da otkazivanje
+// koje stigne između izbora naloga i brisanja ne bi bilo izgubljeno; tada se
+// vraća ErrPurgeNotDue.
+func PurgeUser(userID int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM users WHERE id = ? AND deletion_scheduled_for IS NOT NULL AND deletion_scheduled_for <= NOW() FOR UPDATE", userID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPurgeNotDue
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	for _, table := range UserDataTables {
+		result, err := tx.Exec(table.DeleteQuery, userID)
+		if err != nil {
+			return fmt.Errorf("failed to purge %s: %w", table.Name, err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			slog.Info("purged user data", "table", table.Name, "rows", affected, "user_id", userID)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -1,11 +1,12 @@
 import React, { createContext, useContext, useState, useEffect, ReactNode } from 'react';
 import { authAPI } from '../services/api';
-import { User, LoginRequest, RegisterRequest } from '../services/types';
+import { User, LoginRequest, RegisterRequest, TwoFactorChallenge } from '../services/types';
 
 interface AuthContextType {
   user: User | null;
   loading: boolean;
-  login: (data: LoginRequest) => Promise<void>;
+  login: (data: LoginRequest) => Promise<TwoFactorChallenge | undefined>;
+  completeTwoFactor: (challengeToken: string, code: string) => Promise<void>;
   register: (data: RegisterRequest) => Promise<void>;
   logout: () => void;
   isAuthenticated: boolean;
@@ -34,6 +35,16 @@ export const AuthProvider: React.FC<{ children: ReactNode }> = ({ children }) =>
 
   const login = async (data: LoginRequest) => {
     const response = await authAPI.login(data);
+    // Nalog sa uključenim 2FA: prijava se završava kodom iz authenticator aplikacije
+    if (response.two_factor_required) {
+      return response as TwoFactorChallenge;
+    }
+    setUser(response.user);
+    return undefined;
+  };
+
+  const completeTwoFactor = async (challengeToken: string, code: string) => {
+    const response = await authAPI.loginTwoFactor({ challenge_token: challengeToken, code });
     setUser(response.user);
   };
 
@@ -59,6 +70,7 @@ export const AuthProvider: React.FC<{ children: ReactNode }> = ({ children }) =>
         user,
         loading,
         login,
+        completeTwoFactor,
         register,
         logout,
         isAuthenticated: !!user,
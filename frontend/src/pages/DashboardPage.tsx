@@ -25,7 +25,7 @@ const DashboardPage: React.FC = () => {
       const result = await foodAPI.search(barcode);
       setFood(result);
     } catch (err: any) {
-      setError(err.response?.data || err.message || 'Failed to search food');
+      setError(err.response?.data?.message || err.message || 'Failed to search food');
     } finally {
       setLoading(false);
     }
@@ -40,7 +40,7 @@ const DashboardPage: React.FC = () => {
       const result = await mealPlanAPI.generate();
       setMealPlan(result);
     } catch (err: any) {
-      setError(err.response?.data || err.message || 'Failed to generate meal plan');
+      setError(err.response?.data?.message || err.message || 'Failed to generate meal plan');
     } finally {
       setLoading(false);
     }
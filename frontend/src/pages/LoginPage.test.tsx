@@ -5,11 +5,13 @@ import { MemoryRouter } from 'react-router-dom';
 import LoginPage from './LoginPage';
 
 const mockLogin = vi.fn();
+const mockCompleteTwoFactor = vi.fn();
 const mockNavigate = vi.fn();
 
 vi.mock('../contexts/AuthContext', () => ({
   useAuth: () => ({
     login: mockLogin,
+    completeTwoFactor: mockCompleteTwoFactor,
   }),
 }));
 
@@ -32,6 +34,7 @@ const renderWithRouter = () => {
 describe('LoginPage', () => {
   beforeEach(() => {
     mockLogin.mockReset();
+    mockCompleteTwoFactor.mockReset();
     mockNavigate.mockReset();
   });
 
@@ -67,6 +70,38 @@ describe('LoginPage', () => {
     expect(mockNavigate).toHaveBeenCalledWith('/dashboard');
   });
 
+  it('asks for a verification code when two-factor authentication is enabled', async () => {
+    mockLogin.mockResolvedValueOnce({
+      two_factor_required: true,
+      challenge_token: 'challenge-123',
+      expires_in: 300,
+    });
+    mockCompleteTwoFactor.mockResolvedValueOnce(undefined);
+
+    renderWithRouter();
+
+    fireEvent.change(screen.getByLabelText(/email/i), {
+      target: { value: 'test@example.com' },
+    });
+    fireEvent.change(screen.getByLabelText(/password/i), {
+      target: { value: 'password123' },
+    });
+
+    fireEvent.click(screen.getByRole('button', { name: /login/i }));
+
+    const codeInput = await screen.findByLabelText(/verification code/i);
+    expect(mockNavigate).not.toHaveBeenCalled();
+
+    fireEvent.change(codeInput, { target: { value: '123456' } });
+    fireEvent.click(screen.getByRole('button', { name: /verify/i }));
+
+    await waitFor(() => {
+      expect(mockCompleteTwoFactor).toHaveBeenCalledWith('challenge-123', '123456');
+    });
+
+    expect(mockNavigate).toHaveBeenCalledWith('/dashboard');
+  });
+
   it('shows error message when login fails with response message', async () => {
     mockLogin.mockRejectedValueOnce({
       response: {
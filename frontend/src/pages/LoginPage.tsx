@@ -10,7 +10,9 @@ const LoginPage: React.FC = () => {
   const [password, setPassword] = useState('');
   const [error, setError] = useState('');
   const [loading, setLoading] = useState(false);
-  const { login } = useAuth();
+  const [challengeToken, setChallengeToken] = useState('');
+  const [code, setCode] = useState('');
+  const { login, completeTwoFactor } = useAuth();
   const navigate = useNavigate();
 
   const handleSubmit = async (e: React.FormEvent) => {
@@ -19,7 +21,17 @@ const LoginPage: React.FC = () => {
     setLoading(true);
 
     try {
-      await login({ email, password });
+      if (challengeToken) {
+        await completeTwoFactor(challengeToken, code);
+        navigate('/dashboard');
+        return;
+      }
+      const challenge = await login({ email, password });
+      // Nalog sa uključenim 2FA traži kod iz authenticator aplikacije
+      if (challenge?.two_factor_required) {
+        setChallengeToken(challenge.challenge_token);
+        return;
+      }
       navigate('/dashboard');
     } catch (err: any) {
       // Rukovanje JSON odgovorom sa greškom od backend-a
@@ -41,25 +53,44 @@ const LoginPage: React.FC = () => {
       <Card className="w-full max-w-md" title="Login">
         {error && <div className="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded mb-4">{error}</div>}
         <form onSubmit={handleSubmit} className="space-y-4">
-          <Input
-            label="Email"
-            type="email"
-            value={email}
-            onChange={(e) => setEmail(e.target.value)}
-            required
-            placeholder="your@email.com"
-          />
-          <Input
-            label="Password"
-            type="password"
-            value={password}
-            onChange={(e) => setPassword(e.target.value)}
-            required
-            placeholder="••••••••"
-          />
-          <Button type="submit" disabled={loading} className="w-full">
-            {loading ? 'Logging in...' : 'Login'}
-          </Button>
+          {challengeToken ? (
+            <>
+              <p className="text-gray-600">Enter the code from your authenticator app or one of your recovery codes.</p>
+              <Input
+                label="Verification code"
+                type="text"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                required
+                placeholder="123456"
+              />
+              <Button type="submit" disabled={loading} className="w-full">
+                {loading ? 'Verifying...' : 'Verify'}
+              </Button>
+            </>
+          ) : (
+            <>
+              <Input
+                label="Email"
+                type="email"
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+                required
+                placeholder="your@email.com"
+              />
+              <Input
+                label="Password"
+                type="password"
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+                required
+                placeholder="••••••••"
+              />
+              <Button type="submit" disabled={loading} className="w-full">
+                {loading ? 'Logging in...' : 'Login'}
+              </Button>
+            </>
+          )}
         </form>
         <p className="text-center mt-4 text-gray-600">
           Don't have an account? <Link to="/register" className="text-purple-600 hover:underline">Register</Link>
@@ -22,7 +22,7 @@ const ProfilePage: React.FC = () => {
       await authAPI.getProfile();
       setError('');
     } catch (err: any) {
-      setError(err.response?.data || err.message || 'Failed to fetch profile');
+      setError(err.response?.data?.message || err.message || 'Failed to fetch profile');
     } finally {
       setLoading(false);
     }
@@ -66,6 +66,15 @@ export const authAPI = {
     return response.data;
   },
 
+  loginTwoFactor: async (data: { challenge_token: string; code: string }) => {
+    const response = await api.post('/api/login/2fa', data);
+    if (response.data.token) {
+      localStorage.setItem('token', response.data.token);
+      localStorage.setItem('user', JSON.stringify(response.data.user));
+    }
+    return response.data;
+  },
+
   logout: async () => {
     try {
       await api.post('/api/logout');
@@ -30,6 +30,13 @@ export interface LoginResponse {
   token: string;
 }
 
+// Odgovor na prijavu kada nalog ima uključen 2FA
+export interface TwoFactorChallenge {
+  two_factor_required: true;
+  challenge_token: string;
+  expires_in: number;
+}
+
 // Food types
 export interface Food {
   id?: number;